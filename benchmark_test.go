@@ -2,6 +2,7 @@ package goinmemcache
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -218,3 +219,92 @@ func BenchmarkMixedOperations(b *testing.B) {
 		}
 	})
 }
+
+// benchmarkSkewedWorkload replays a Zipf distributed key stream against a
+// bounded cache, filling misses like a read-through caller, and reports the
+// hit ratio alongside the usual timings
+func benchmarkSkewedWorkload(b *testing.B, eviction EvictionMode) {
+	maxItems := int64(1000)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: eviction})
+	zipf := rand.NewZipf(rand.New(rand.NewSource(42)), 1.01, 1, 100000)
+
+	keys := make([]int, 1<<16)
+	for i := range keys {
+		keys[i] = int(zipf.Uint64())
+	}
+
+	hits := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i&(len(keys)-1)]
+		if _, found := cache.Get(key); found {
+			hits++
+		} else {
+			value := key
+			cache.Set(key, &value)
+		}
+	}
+	b.ReportMetric(float64(hits)/float64(b.N), "hit-ratio")
+}
+
+func BenchmarkSkewedLRU(b *testing.B) {
+	benchmarkSkewedWorkload(b, EvictionLRU)
+}
+
+func BenchmarkSkewedS3FIFO(b *testing.B) {
+	benchmarkSkewedWorkload(b, EvictionS3FIFO)
+}
+
+// benchmarkSetWithTTL mirrors BenchmarkCacheSetWithTTL for an expiration backend
+func benchmarkSetWithTTL(b *testing.B, expiration ExpirationMode) {
+	cache := New[string, int](&Config{Expiration: expiration})
+	ttl := time.Hour
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		value := i
+		cache.SetWithTTL(key, &value, ttl)
+	}
+}
+
+func BenchmarkSetWithTTLHeap(b *testing.B) {
+	benchmarkSetWithTTL(b, ExpirationHeap)
+}
+
+func BenchmarkSetWithTTLTimingWheel(b *testing.B) {
+	benchmarkSetWithTTL(b, ExpirationTimingWheel)
+}
+
+func BenchmarkSetWithTTLSampling(b *testing.B) {
+	benchmarkSetWithTTL(b, ExpirationSampling)
+}
+
+// benchmarkTTLChurn rewrites keys of a large cache with varying TTLs, so
+// every set reschedules an existing expiration
+func benchmarkTTLChurn(b *testing.B, expiration ExpirationMode) {
+	const keyCount = 1 << 18
+	cache := New[int, int](&Config{Expiration: expiration})
+	for i := 0; i < keyCount; i++ {
+		cache.SetWithTTL(i, &i, time.Hour)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := i & (keyCount - 1)
+		ttl := time.Duration(30+i%3600) * time.Second
+		cache.SetWithTTL(key, &i, ttl)
+	}
+}
+
+func BenchmarkTTLChurnHeap(b *testing.B) {
+	benchmarkTTLChurn(b, ExpirationHeap)
+}
+
+func BenchmarkTTLChurnTimingWheel(b *testing.B) {
+	benchmarkTTLChurn(b, ExpirationTimingWheel)
+}
+
+func BenchmarkTTLChurnSampling(b *testing.B) {
+	benchmarkTTLChurn(b, ExpirationSampling)
+}
@@ -1,65 +1,165 @@
 package goinmemcache
 
 import (
-	"container/heap"
+	"errors"
+	"math/rand/v2"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Config struct {
 	Size     *int64
 	MaxItems *int64
-}
+
+	// Eviction selects a built-in eviction policy (LRU by default). Pass
+	// WithEvictionPolicy to New to plug in a custom one.
+	Eviction EvictionMode
+	// ProtectedRatio is the share of keys EvictionSLRU keeps in its
+	// protected segment, between 0 and 1 (0.8 when unset)
+	ProtectedRatio float64
+	// EvictionSamples is how many keys the sampled eviction modes inspect
+	// per eviction (5 when unset)
+	EvictionSamples int
+
+	// DeepSize makes the built-in size estimate walk values recursively,
+	// counting string and slice backing arrays, map buckets and pointed-to
+	// data. Shared and cyclic references are counted once.
+	DeepSize bool
+
+	// SlidingTTL makes every SetWithTTL entry behave like SetWithSlidingTTL
+	SlidingTTL bool
+	// MaxLifetime caps how long reads can keep a sliding entry alive,
+	// measured from its last write. Zero means no cap.
+	MaxLifetime time.Duration
+
+	// DefaultTTL is the TTL applied by Set and SetWithCost when no Expiry
+	// is passed with WithExpiry. Zero means items stored with them never
+	// expire.
+	DefaultTTL time.Duration
+	// TTLJitter adds a random duration in [0, TTLJitter) to every TTL when
+	// an item is written and to every new lifetime an Expiry returns on
+	// read, spreading out the expiry of items loaded together. Sliding
+	// renewals reuse the TTL jittered at write time, and absolute deadlines
+	// are not jittered.
+	TTLJitter time.Duration
+
+	// CleanupInterval is how often the background goroutine removes
+	// expired items (one minute when unset)
+	CleanupInterval time.Duration
+	// PreciseExpiration replaces the periodic cleanup with a single timer
+	// re-armed to the earliest expiration, so expired items are removed
+	// close to their exact expiry
+	PreciseExpiration bool
+	// Clock supplies the time for TTLs and background expiration (the
+	// system clock when unset). Tests can pass a FakeClock.
+	Clock Clock
+
+	// BatchWindow is how long GetAll waits to coalesce misses from
+	// concurrent callers into a single bulk load. Zero loads immediately.
+	BatchWindow time.Duration
+
+	// CleanupBatchSize is the most expired items a cleanup removes while
+	// holding the write lock. Larger sweeps release the lock between
+	// batches so readers are not stalled (1000 when unset).
+	CleanupBatchSize int
+	// Expiration selects how expirations are tracked (ExpirationHeap by
+	// default)
+	Expiration ExpirationMode
+}
+
+// Sizer can be implemented by value types that know their own memory
+// footprint. When V implements it, the cache uses CacheSize instead of
+// estimating the value's size with reflection.
+type Sizer interface {
+	CacheSize() int64
+}
+
+// sizerType is the reflect type of the Sizer interface
+var sizerType = reflect.TypeOf((*Sizer)(nil)).Elem()
+
+// ErrCacheFull is returned by the set methods when an item does not fit
+// within the configured limits and the remaining items cannot be evicted,
+// because they are pinned or, in the volatile eviction modes, have no TTL
+var ErrCacheFull = errors.New("goinmemcache: cache is full and nothing can be evicted")
 
 type Cache[K comparable, V any] interface {
-	Set(key K, value *V)
-	SetWithTTL(key K, value *V, ttl time.Duration)
+	Set(key K, value *V) error
+	SetWithTTL(key K, value *V, ttl time.Duration) error
+	SetWithCost(key K, value *V, cost int64) error                        // Cost weighs the item for EvictionGDSF
+	SetWithSlidingTTL(key K, value *V, ttl time.Duration) error           // Each read restarts the TTL
+	SetWithDeadline(key K, value *V, deadline time.Time) error            // Expire at an absolute time
+	SetWithSoftTTL(key K, value *V, softTTL, hardTTL time.Duration) error // Stale after softTTL, gone after hardTTL
 	Get(key K) (*V, bool)
+	GetWithStatus(key K) (*V, EntryStatus) // Also reports whether the item is past its soft TTL
 	Delete(key K)
+	ExpireAt(key K, deadline time.Time) bool // Set an absolute expiry on an existing item
+	TTL(key K) (time.Duration, bool)         // Remaining lifetime, NeverExpire if none
+	Persist(key K) bool                      // Remove an item's expiration
+	Pin(key K) bool                          // Protect an item from eviction; reports whether it exists
+	Unpin(key K) bool                        // Make a pinned item evictable again
 	Len() int
 	Clear()
 	Close()
-	CleanupExpired() // Manually trigger cleanup of expired items
-}
-
-// listNode represents a node in the doubly-linked list for LRU ordering
-type listNode[K comparable] struct {
-	key  K
-	prev *listNode[K]
-	next *listNode[K]
+	CleanupExpired() int // Manually trigger cleanup of expired items; returns how many were removed
 }
 
 type cache[K comparable, V any] struct {
-	mu        sync.RWMutex
-	size      *int64
-	sizeBytes int64
-	maxItems  *int64
+	mu         sync.RWMutex
+	size       *int64
+	sizeBytes  int64
+	maxItems   *int64
+	pinned     int   // number of pinned items
+	pinnedSize int64 // bytes held by pinned items
 
-	// Doubly-linked list for O(1) LRU operations
-	head *listNode[K] // dummy head node
-	tail *listNode[K] // dummy tail node
+	// Eviction policy deciding which key to drop when a limit is reached
+	policy           EvictionPolicy[K]
+	concurrentAccess bool // policy allows Get under the read lock
 
 	items map[K]*cacheItem[K, V] // map to store actual data for fast access
 
 	// Optimized TTL expiration management
-	expirationQueue []*expirationEntry[K]     // min-heap of expiration entries
-	expirationMap   map[K]*expirationEntry[K] // fast lookup for expiration entries
-	cleanupTicker   *time.Ticker              // single ticker for all TTL cleanup
-	stopChan        chan struct{}             // channel to stop background cleanup
+	expirations   expirationQueue[K] // tracks when keys with a TTL expire
+	cleanupTicker Ticker             // single ticker for all TTL cleanup
+	expiryTimer   Timer              // precise mode timer for the earliest expiration
+	timerDeadline time.Time          // when expiryTimer fires, zero if stopped
+	cleanupBatch  int                // most items expired per lock hold
+	stopChan      chan struct{}      // channel to stop background cleanup
+	clock         Clock              // source of the current time
 
 	// Size calculation optimization
 	keyTypeSize   int64 // cached size for key type
 	valueTypeSize int64 // cached size for value type (for fixed-size types)
 	isKeyString   bool  // whether key type is string
 	isValueString bool  // whether value type is string
+	isValueSizer  bool  // whether value type implements Sizer
+	isValuePtr    bool  // whether value type is a pointer
+	isPtrSizer    bool  // whether pointer to value type implements Sizer
+	deepSize      bool  // whether values are measured recursively
+
+	weigher func(K, V) int64 // user-supplied size function, overrides estimation
+
+	slidingTTL  bool          // SetWithTTL entries slide on read
+	maxLifetime time.Duration // cap on sliding renewals
+	expiry      Expiry[K, V]  // user-supplied per-item lifetimes
+	defaultTTL  time.Duration // TTL applied by Set
+	ttlJitter   time.Duration // random spread added to TTLs
 }
 
-// expirationEntry represents an item in the expiration queue
-type expirationEntry[K comparable] struct {
-	key        K
-	expireTime time.Time
-	index      int // index in the heap
+// defaultItemCost is the cost of items stored without SetWithCost
+const defaultItemCost = 1
+
+// defaultCleanupBatch is the most expired items removed per lock hold
+const defaultCleanupBatch = 1000
+
+// itemOptions carries the per-item settings of a set operation
+type itemOptions struct {
+	ttl      *time.Duration // nil means the item never expires
+	cost     int64
+	sliding  bool          // reads extend the TTL
+	deadline time.Time     // absolute expiry, overrides ttl when set
+	softTTL  time.Duration // the item is stale after softTTL, zero for never
 }
 
 type cacheItem[K comparable, V any] struct {
@@ -67,45 +167,28 @@ type cacheItem[K comparable, V any] struct {
 	TTL       *time.Duration
 	CreatedAt time.Time
 	Size      int64
-	Node      *listNode[K] // reference to the node in the doubly-linked list
-}
+	Cost      int64 // recomputation cost used by EvictionGDSF
+	Pinned    bool  // pinned items are never evicted
 
-// expirationHeap implements heap.Interface for expiration entries
-type expirationHeap[K comparable] []*expirationEntry[K]
+	// Sliding items have CreatedAt reset on every read, up to MaxExpiry
+	Sliding   bool
+	MaxExpiry time.Time // absolute expiry cap for sliding items, zero for none
 
-func (h expirationHeap[K]) Len() int           { return len(h) }
-func (h expirationHeap[K]) Less(i, j int) bool { return h[i].expireTime.Before(h[j].expireTime) }
-func (h expirationHeap[K]) Swap(i, j int) {
-	h[i], h[j] = h[j], h[i]
-	h[i].index = i
-	h[j].index = j
-}
-
-func (h *expirationHeap[K]) Push(x interface{}) {
-	entry := x.(*expirationEntry[K])
-	entry.index = len(*h)
-	*h = append(*h, entry)
-}
+	// StaleAt is when the item passes its soft TTL, zero for never
+	StaleAt time.Time
 
-func (h *expirationHeap[K]) Pop() interface{} {
-	old := *h
-	n := len(old)
-	entry := old[n-1]
-	entry.index = -1
-	*h = old[0 : n-1]
-	return entry
+	// LastAccess holds the unix nanoseconds of the last write or read; only
+	// maintained by the sampled eviction policies
+	LastAccess atomic.Int64
 }
 
-func New[K comparable, V any](config *Config) Cache[K, V] {
+// New creates a cache with the given configuration, which may be nil. Settings
+// typed by K and V, such as a custom eviction policy, are passed as options.
+func New[K comparable, V any](config *Config, opts ...Option[K, V]) Cache[K, V] {
 	if config == nil {
 		config = &Config{}
 	}
-
-	// Create dummy head and tail nodes for the doubly-linked list
-	head := &listNode[K]{}
-	tail := &listNode[K]{}
-	head.next = tail
-	tail.prev = head
+	o := newOptions(opts)
 
 	// Pre-calculate type information for size calculations
 	var zeroK K
@@ -115,6 +198,8 @@ func New[K comparable, V any](config *Config) Cache[K, V] {
 
 	isKeyString := keyType.Kind() == reflect.String
 	isValueString := valueType.Kind() == reflect.String
+	isValueSizer := valueType.Implements(sizerType)
+	isPtrSizer := !isValueSizer && reflect.PointerTo(valueType).Implements(sizerType)
 
 	var keyTypeSize, valueTypeSize int64
 	if !isKeyString {
@@ -125,193 +210,358 @@ func New[K comparable, V any](config *Config) Cache[K, V] {
 	}
 
 	c := &cache[K, V]{
-		size:            config.Size,
-		maxItems:        config.MaxItems,
-		head:            head,
-		tail:            tail,
-		items:           make(map[K]*cacheItem[K, V]),
-		expirationQueue: make([]*expirationEntry[K], 0),
-		expirationMap:   make(map[K]*expirationEntry[K]),
-		stopChan:        make(chan struct{}),
-		keyTypeSize:     keyTypeSize,
-		valueTypeSize:   valueTypeSize,
-		isKeyString:     isKeyString,
-		isValueString:   isValueString,
-	}
-
-	// Start the cleanup ticker for periodic expiration check
-	c.cleanupTicker = time.NewTicker(time.Minute)
+		size:          config.Size,
+		maxItems:      config.MaxItems,
+		items:         make(map[K]*cacheItem[K, V]),
+		stopChan:      make(chan struct{}),
+		keyTypeSize:   keyTypeSize,
+		valueTypeSize: valueTypeSize,
+		isKeyString:   isKeyString,
+		isValueString: isValueString,
+		isValueSizer:  isValueSizer,
+		isValuePtr:    valueType.Kind() == reflect.Ptr,
+		isPtrSizer:    isPtrSizer,
+		deepSize:      config.DeepSize,
+		slidingTTL:    config.SlidingTTL,
+		maxLifetime:   config.MaxLifetime,
+		defaultTTL:    config.DefaultTTL,
+		ttlJitter:     config.TTLJitter,
+		clock:         config.Clock,
+		weigher:       o.weigher,
+		expiry:        o.expiry,
+	}
+	if c.clock == nil {
+		c.clock = systemClock{}
+	}
+	c.expirations = newExpirationQueue[K](config.Expiration, c.clock.Now())
+
+	c.policy = c.newPolicy(config, o.evictionPolicy)
+	if p, ok := c.policy.(ConcurrentAccessPolicy); ok {
+		c.concurrentAccess = p.ConcurrentAccess()
+	}
+
+	c.cleanupBatch = config.CleanupBatchSize
+	if c.cleanupBatch <= 0 {
+		c.cleanupBatch = defaultCleanupBatch
+	}
+	c.startCleanup(config)
+
+	return c
+}
+
+// startCleanup starts the background goroutine that removes expired items,
+// driven either by a periodic ticker or by the precise expiration timer
+func (c *cache[K, V]) startCleanup(config *Config) {
+	var tick, fire <-chan time.Time
+	if config.PreciseExpiration && config.Expiration != ExpirationSampling {
+		c.expiryTimer = c.clock.NewTimer(time.Hour)
+		c.expiryTimer.Stop()
+		fire = c.expiryTimer.C()
+	} else {
+		interval := config.CleanupInterval
+		if interval <= 0 {
+			interval = time.Minute
+			if config.Expiration == ExpirationSampling {
+				interval = activeExpireInterval
+			}
+		}
+		c.cleanupTicker = c.clock.NewTicker(interval)
+		tick = c.cleanupTicker.C()
+	}
+
 	go func() {
 		for {
 			select {
-			case <-c.cleanupTicker.C:
+			case <-tick:
+				c.cleanupExpiredItems()
+			case <-fire:
 				c.cleanupExpiredItems()
 			case <-c.stopChan:
-				c.cleanupTicker.Stop()
 				return
 			}
 		}
 	}()
+}
 
-	return c
+func (c *cache[K, V]) Set(key K, value *V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.setItem(key, value, c.defaultOptions(defaultItemCost))
+}
+
+// defaultOptions returns the options of a write without an explicit
+// lifetime: the default TTL applies unless an Expiry is configured
+func (c *cache[K, V]) defaultOptions(cost int64) itemOptions {
+	opts := itemOptions{cost: cost}
+	if c.defaultTTL > 0 && c.expiry == nil {
+		ttl := c.defaultTTL
+		opts.ttl = &ttl
+		opts.sliding = c.slidingTTL
+	}
+	return opts
 }
 
-func (c *cache[K, V]) Set(key K, value *V) {
+func (c *cache[K, V]) SetWithTTL(key K, value *V, ttl time.Duration) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.setItem(key, value, nil)
+	return c.setItem(key, value, itemOptions{ttl: &ttl, cost: defaultItemCost, sliding: c.slidingTTL})
 }
 
-func (c *cache[K, V]) SetWithTTL(key K, value *V, ttl time.Duration) {
+// SetWithCost stores a value like Set, together with the cost of
+// recomputing it. Only EvictionGDSF takes the cost into account.
+func (c *cache[K, V]) SetWithCost(key K, value *V, cost int64) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.setItem(key, value, &ttl)
+	return c.setItem(key, value, c.defaultOptions(cost))
+}
 
-	// Remove any existing expiration entry for this key
-	c.removeExpirationEntry(key)
+// SetWithSlidingTTL stores a value that expires after ttl without reads.
+// Every successful Get restarts the TTL, up to Config.MaxLifetime.
+func (c *cache[K, V]) SetWithSlidingTTL(key K, value *V, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Add new expiration entry if TTL is positive
-	if ttl > 0 {
-		expireTime := time.Now().Add(ttl)
-		c.addExpirationEntry(key, expireTime)
-	}
+	return c.setItem(key, value, itemOptions{ttl: &ttl, cost: defaultItemCost, sliding: true})
 }
 
 func (c *cache[K, V]) Get(key K) (*V, bool) {
+	value, status := c.getWithStatus(key)
+	return value, status != EntryMissing
+}
+
+// getWithStatus looks up a key, records the access and reports whether
+// the item is fresh or stale
+func (c *cache[K, V]) getWithStatus(key K) (*V, EntryStatus) {
+	if c.concurrentAccess {
+		// Reads only need the shared lock unless the lifetime must be renewed
+		c.mu.RLock()
+		item, exists := c.items[key]
+		if !exists || (!item.Sliding && c.expiry == nil) {
+			defer c.mu.RUnlock()
+			if exists && c.isItemValid(item) {
+				c.policy.RecordAccess(key)
+				return item.Value, c.itemStatus(item) // Item found and valid
+			}
+			return nil, EntryMissing // Item not found
+		}
+		c.mu.RUnlock()
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if item, exists := c.items[key]; exists {
 		if c.isItemValid(item) {
-			// Move to tail (most recently used position)
-			c.moveToTail(item.Node)
-			return item.Value, true // Item found and valid
+			c.policy.RecordAccess(key)
+			status := c.itemStatus(item)
+			if item.Sliding {
+				c.touchSliding(key, item)
+			} else if c.expiry != nil {
+				c.applyReadExpiry(key, item)
+			}
+			return item.Value, status // Item found and valid
 		}
 	}
 
-	return nil, false // Item not found
+	return nil, EntryMissing // Item not found
 }
 
 func (c *cache[K, V]) Delete(key K) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Single lookup for item
-	item, itemExists := c.items[key]
-
-	if itemExists {
-		// Update current size
-		c.sizeBytes -= item.Size
-
-		// Remove from items map
-		delete(c.items, key)
-
-		// Remove from doubly-linked list
-		c.removeNode(item.Node)
-
-		// Remove from expiration queue
-		c.removeExpirationEntry(key)
-	}
+	c.removeItemByKey(key)
 }
 
 // updateOrAddItem updates an existing item or adds a new one
 func (c *cache[K, V]) updateOrAddItem(key K, item *cacheItem[K, V]) {
 	if existingItem, exists := c.items[key]; exists {
-		// Update existing item - reuse the same node and move to tail
+		// Update existing item in place
 		existingItem.Value = item.Value
 		existingItem.TTL = item.TTL
 		existingItem.CreatedAt = item.CreatedAt
 		existingItem.Size = item.Size
-		c.moveToTail(existingItem.Node)
-		c.items[key] = existingItem
+		existingItem.Cost = item.Cost
+		existingItem.Sliding = item.Sliding
+		existingItem.MaxExpiry = item.MaxExpiry
+		existingItem.StaleAt = item.StaleAt
+		c.policy.RecordAccess(key)
 	} else {
-		// Add new item - create new node and add to tail
-		node := &listNode[K]{key: key}
-		item.Node = node
-		c.addToTail(node)
 		c.items[key] = item
+		c.policy.RecordInsert(key)
 	}
 }
 
 // isItemValid checks if a cache item is valid (not expired)
 func (c *cache[K, V]) isItemValid(item *cacheItem[K, V]) bool {
-	if item.TTL == nil {
+	expireTime, expires := c.expireTime(item)
+	if !expires {
 		return true // No TTL means never expires
 	}
-	return time.Since(item.CreatedAt) < *item.TTL
+	return c.clock.Now().Before(expireTime)
+}
+
+// expireTime returns when an item expires, capped by its MaxExpiry, and
+// reports false for items without a TTL
+func (c *cache[K, V]) expireTime(item *cacheItem[K, V]) (time.Time, bool) {
+	if item.TTL == nil {
+		return time.Time{}, false
+	}
+	expireTime := item.CreatedAt.Add(*item.TTL)
+	if !item.MaxExpiry.IsZero() && item.MaxExpiry.Before(expireTime) {
+		expireTime = item.MaxExpiry
+	}
+	return expireTime, true
+}
+
+// touchSliding restarts a sliding item's TTL, including any jitter it got
+// when written, and reschedules its expiration
+func (c *cache[K, V]) touchSliding(key K, item *cacheItem[K, V]) {
+	item.CreatedAt = c.clock.Now()
+	expireTime, _ := c.expireTime(item)
+	c.scheduleExpiration(key, expireTime)
 }
 
 // setItem is a helper method that consolidates the logic for setting cache items
-func (c *cache[K, V]) setItem(key K, value *V, ttl *time.Duration) {
-	var itemSize int64
-	if value != nil {
-		itemSize = c.fastCalculateItemSize(key, *value)
+func (c *cache[K, V]) setItem(key K, value *V, opts itemOptions) error {
+	now := c.clock.Now()
+	if !opts.deadline.IsZero() {
+		// Derive the TTL from the same instant used for CreatedAt so the
+		// item expires exactly at the deadline
+		ttl := opts.deadline.Sub(now)
+		opts.ttl = &ttl
 	} else {
-		itemSize = c.fastCalculateItemSize(key, *new(V)) // For nil values, calculate size of zero value
+		if opts.ttl == nil && c.expiry != nil {
+			opts.ttl = c.expiryTTL(key, value, now)
+		}
+		if opts.ttl != nil && c.ttlJitter > 0 {
+			ttl := c.jitterTTL(*opts.ttl)
+			opts.ttl = &ttl
+		}
 	}
 
-	// If updating existing item, handle size difference
-	if existingItem, exists := c.items[key]; exists {
-		oldSize := existingItem.Size
-		newTotalSize := c.sizeBytes - oldSize + itemSize
-
-		// Evict items if the update would exceed limits
-		for (c.size != nil && newTotalSize > *c.size) ||
-			(c.maxItems != nil && int64(len(c.items)) >= *c.maxItems) {
-			if c.listSize() <= 1 { // Don't evict the item we're updating
-				break
-			}
-			// Find an item to evict that's not the one we're updating
-			if !c.isEmpty() && c.head.next.key == key && c.listSize() > 1 {
-				// If the first item is the one we're updating, evict the second
-				secondNode := c.head.next.next
-				if secondNode != c.tail {
-					c.removeItemByKey(secondNode.key)
-				}
-			} else {
-				c.removeOldestItem()
-			}
-			newTotalSize = c.sizeBytes - oldSize + itemSize
-		}
+	if opts.ttl != nil && *opts.ttl <= 0 {
+		// The item would expire on arrival; drop any old value without
+		// evicting others to make room for it
+		c.removeItemByKey(key)
+		return nil
+	}
+
+	var itemValue V // For nil values, calculate size of zero value
+	if value != nil {
+		itemValue = *value
+	}
 
-		c.sizeBytes = newTotalSize
+	var itemSize int64
+	if c.weigher != nil {
+		itemSize = c.weigher(key, itemValue)
 	} else {
-		// Adding new item - evict items if necessary before adding
-		newTotalSize := c.sizeBytes + itemSize
+		itemSize = c.fastCalculateItemSize(key, itemValue)
+	}
 
-		for (c.size != nil && newTotalSize > *c.size) ||
-			(c.maxItems != nil && int64(len(c.items)) >= *c.maxItems) {
-			if c.isEmpty() {
-				break // No items to evict
-			}
-			c.removeOldestItem()
-			newTotalSize = c.sizeBytes + itemSize
+	// Evict items if the new or updated item would exceed limits
+	if !c.makeRoom(key, itemSize) {
+		return ErrCacheFull
+	}
+	if existingItem, exists := c.items[key]; exists {
+		c.sizeBytes += itemSize - existingItem.Size
+		if existingItem.Pinned {
+			c.pinnedSize += itemSize - existingItem.Size
 		}
-
-		c.sizeBytes = newTotalSize
+	} else {
+		c.sizeBytes += itemSize
 	}
 
 	item := &cacheItem[K, V]{
 		Value:     value,
-		TTL:       ttl,
-		CreatedAt: time.Now(),
+		TTL:       opts.ttl,
+		CreatedAt: now,
 		Size:      itemSize,
+		Cost:      opts.cost,
+		Sliding:   opts.sliding && opts.ttl != nil,
+	}
+	if item.Sliding && c.maxLifetime > 0 {
+		item.MaxExpiry = now.Add(c.maxLifetime)
+	}
+	if opts.softTTL > 0 {
+		item.StaleAt = now.Add(opts.softTTL)
 	}
 
 	c.updateOrAddItem(key, item)
 
 	// Manage expiration queue for TTL
-	if ttl != nil {
-		if *ttl > 0 {
-			expireTime := time.Now().Add(*ttl)
-			c.addExpirationEntry(key, expireTime)
-		} else {
-			// Zero or negative TTL removes the item immediately
-			c.removeItemByKey(key)
+	if opts.ttl == nil {
+		c.removeExpirationEntry(key)
+	} else {
+		expireTime, _ := c.expireTime(item)
+		c.scheduleExpiration(key, expireTime)
+	}
+	return nil
+}
+
+// jitterTTL adds a random spread of up to ttlJitter to a positive TTL
+func (c *cache[K, V]) jitterTTL(ttl time.Duration) time.Duration {
+	if c.ttlJitter <= 0 || ttl <= 0 || ttl > NeverExpire-c.ttlJitter {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(int64(c.ttlJitter)))
+}
+
+// makeRoom evicts items chosen by the eviction policy until storing key
+// with itemSize bytes stays within the limits. The key being set and pinned
+// items are never chosen as victims. It reports false, without evicting
+// anything, when the pinned items alone leave no room for the item, and
+// false when the policy runs out of victims while other items remain, in
+// which case the victims evicted so far stay evicted. An
+// item that is simply larger than the limits of an otherwise empty cache is
+// still admitted.
+func (c *cache[K, V]) makeRoom(key K, itemSize int64) bool {
+	sizeDelta, countDelta := itemSize, int64(1)
+	otherPinned, otherPinnedSize := c.pinned, c.pinnedSize
+	item, exists := c.items[key]
+	if exists {
+		sizeDelta, countDelta = itemSize-item.Size, 0
+		if item.Pinned {
+			otherPinned--
+			otherPinnedSize -= item.Size
+		}
+	}
+
+	// Evicting every unpinned item would not help, so fail up front
+	if otherPinned > 0 &&
+		((c.size != nil && otherPinnedSize+itemSize > *c.size) ||
+			(c.maxItems != nil && int64(otherPinned)+1 > *c.maxItems)) {
+		return false
+	}
+
+	skip := func(k K) bool {
+		return k == key || (c.pinned > 0 && c.items[k].Pinned)
+	}
+	for c.exceedsLimits(sizeDelta, countDelta) {
+		victim, ok := c.policy.Victim(skip)
+		if !ok {
+			// Nothing left the policy may evict; fail unless the cache is
+			// otherwise empty, as pinned items or, in the volatile modes,
+			// keys without a TTL are in the way
+			others := len(c.items)
+			if exists {
+				others--
+			}
+			return others == 0
 		}
+		c.removeItemByKey(victim)
 	}
+	return true
+}
+
+// exceedsLimits reports whether growing the cache by sizeDelta bytes and
+// countDelta items would exceed the configured limits
+func (c *cache[K, V]) exceedsLimits(sizeDelta, countDelta int64) bool {
+	return (c.size != nil && c.sizeBytes+sizeDelta > *c.size) ||
+		(c.maxItems != nil && int64(len(c.items))+countDelta > *c.maxItems)
 }
 
 // removeItemByKey removes an item by its key
@@ -322,31 +572,22 @@ func (c *cache[K, V]) removeItemByKey(key K) {
 	if itemExists {
 		// Update current size
 		c.sizeBytes -= item.Size
+		if item.Pinned {
+			c.pinned--
+			c.pinnedSize -= item.Size
+		}
 
 		// Remove from items map
 		delete(c.items, key)
 
-		// Remove from doubly-linked list
-		c.removeNode(item.Node)
+		// Let the eviction policy forget the key
+		c.policy.RecordRemoval(key)
 
 		// Remove from expiration queue
 		c.removeExpirationEntry(key)
 	}
 }
 
-// removeOldestItem removes the oldest (first) item from the cache
-func (c *cache[K, V]) removeOldestItem() {
-	if c.isEmpty() {
-		return
-	}
-
-	// Get the oldest item key (first in the doubly-linked list)
-	oldestNode := c.removeHead()
-	if oldestNode != nil {
-		c.removeItemByKey(oldestNode.key)
-	}
-}
-
 // expireKey removes an expired key (called from background cleanup)
 func (c *cache[K, V]) expireKey(key K) {
 	c.mu.Lock()
@@ -361,54 +602,64 @@ func (c *cache[K, V]) expireKey(key K) {
 	}
 }
 
-// cleanupExpiredItems removes expired items from the cache
-func (c *cache[K, V]) cleanupExpiredItems() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	now := time.Now()
-	h := (*expirationHeap[K])(&c.expirationQueue)
-
-	// Remove expired items from the front of the heap
-	for h.Len() > 0 {
-		entry := (*h)[0]
-		if entry.expireTime.After(now) {
-			break // No more expired items
+// cleanupExpiredItems removes expired items from the cache in batches of
+// at most cleanupBatch, releasing the write lock between batches, and
+// returns how many items were removed
+func (c *cache[K, V]) cleanupExpiredItems() int {
+	now := c.clock.Now()
+	removed := 0
+	for {
+		c.mu.Lock()
+		more := c.expirations.expire(now, c.cleanupBatch, func(key K) {
+			// Check if item still exists and is expired
+			if item, exists := c.items[key]; exists {
+				if !c.isItemValid(item) {
+					c.removeItemByKey(key)
+					removed++
+				}
+			}
+		})
+		if !more {
+			break
 		}
+		c.mu.Unlock()
+	}
+	defer c.mu.Unlock()
 
-		// Remove expired entry from heap
-		heap.Pop(h)
-		delete(c.expirationMap, entry.key)
-
-		// Check if item still exists and is expired
-		if item, exists := c.items[entry.key]; exists {
-			if !c.isItemValid(item) {
-				c.removeItemByKey(entry.key)
-			}
+	if c.expiryTimer != nil {
+		// Re-arm for the new earliest expiration
+		c.timerDeadline = time.Time{}
+		if next, ok := c.expirations.next(); ok {
+			c.armExpiryTimer(next)
 		}
 	}
+	return removed
 }
 
-// addExpirationEntry adds an entry to the expiration queue
-func (c *cache[K, V]) addExpirationEntry(key K, expireTime time.Time) {
-	entry := &expirationEntry[K]{
-		key:        key,
-		expireTime: expireTime,
+// armExpiryTimer makes the precise expiration timer fire no later than
+// expireTime. The timer may fire early after entries are removed, in which
+// case the cleanup simply re-arms it.
+func (c *cache[K, V]) armExpiryTimer(expireTime time.Time) {
+	if c.expiryTimer == nil {
+		return
+	}
+	if !c.timerDeadline.IsZero() && !expireTime.Before(c.timerDeadline) {
+		return
 	}
-	h := (*expirationHeap[K])(&c.expirationQueue)
-	heap.Push(h, entry)
-	c.expirationMap[key] = entry
+	c.timerDeadline = expireTime
+	c.expiryTimer.Reset(expireTime.Sub(c.clock.Now()))
 }
 
-// removeExpirationEntry removes an entry from the expiration queue
+// scheduleExpiration sets the expiration time of a key in the expiration
+// queue, adding it if needed
+func (c *cache[K, V]) scheduleExpiration(key K, expireTime time.Time) {
+	c.expirations.schedule(key, expireTime)
+	c.armExpiryTimer(expireTime)
+}
+
+// removeExpirationEntry removes a key from the expiration queue
 func (c *cache[K, V]) removeExpirationEntry(key K) {
-	if entry, exists := c.expirationMap[key]; exists {
-		h := (*expirationHeap[K])(&c.expirationQueue)
-		if entry.index >= 0 && entry.index < len(*h) {
-			heap.Remove(h, entry.index)
-		}
-		delete(c.expirationMap, key)
-	}
+	c.expirations.remove(key)
 }
 
 // fastCalculateItemSize is an optimized version of calculateItemSize that uses cached type information
@@ -425,7 +676,18 @@ func (c *cache[K, V]) fastCalculateItemSize(key K, value V) int64 {
 	}
 
 	// Calculate value size using cached information
-	if c.isValueString {
+	if c.isValueSizer && c.isValuePtr && reflect.ValueOf(value).IsNil() {
+		// A nil pointer cannot report its size, count the pointer itself
+		size += 8
+	} else if c.isValueSizer {
+		// Values that implement Sizer report their own size
+		size += any(value).(Sizer).CacheSize()
+	} else if c.isPtrSizer {
+		size += any(&value).(Sizer).CacheSize()
+	} else if c.deepSize {
+		// Opt-in recursive walk using cached per-type plans
+		size += deepValueSize(value)
+	} else if c.isValueString {
 		// For strings, calculate actual length
 		size += int64(len(any(value).(string)))
 	} else if c.valueTypeSize > 0 {
@@ -439,7 +701,7 @@ func (c *cache[K, V]) fastCalculateItemSize(key K, value V) int64 {
 	// Add overhead for the cache item struct itself (pre-calculated constants)
 	size += 32 // time.Time (24) + int64 Size field (8)
 	size += 8  // TTL pointer
-	size += 8  // Node pointer
+	size += 8  // eviction policy bookkeeping
 
 	return size
 }
@@ -470,53 +732,41 @@ func (c *cache[K, V]) calculateComplexValueSize(value V) int64 {
 	}
 }
 
-// moveToTail moves a node to the tail (most recently used position)
-func (c *cache[K, V]) moveToTail(node *listNode[K]) {
-	c.removeNode(node)
-	c.addToTail(node)
-}
-
-// addToTail adds a node right before the tail (most recently used position)
-func (c *cache[K, V]) addToTail(node *listNode[K]) {
-	prev := c.tail.prev
-	prev.next = node
-	node.prev = prev
-	node.next = c.tail
-	c.tail.prev = node
-}
-
-// removeNode removes a node from the doubly-linked list
-func (c *cache[K, V]) removeNode(node *listNode[K]) {
-	prev := node.prev
-	next := node.next
-	prev.next = next
-	next.prev = prev
-}
+// Pin protects an item from eviction. Its bytes still count toward the
+// size limit and it still expires if it has a TTL. Pin reports false if
+// the key is not in the cache.
+func (c *cache[K, V]) Pin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-// removeTail removes and returns the least recently used node (right before tail)
-func (c *cache[K, V]) removeHead() *listNode[K] {
-	head := c.head.next
-	if head == c.tail {
-		return nil // list is empty
+	item, exists := c.items[key]
+	if !exists {
+		return false
 	}
-	c.removeNode(head)
-	return head
+	if !item.Pinned {
+		item.Pinned = true
+		c.pinned++
+		c.pinnedSize += item.Size
+	}
+	return true
 }
 
-// isEmpty checks if the list is empty
-func (c *cache[K, V]) isEmpty() bool {
-	return c.head.next == c.tail
-}
+// Unpin makes a pinned item evictable again. It reports false if the key
+// is not in the cache.
+func (c *cache[K, V]) Unpin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-// listSize returns the number of nodes in the list
-func (c *cache[K, V]) listSize() int {
-	count := 0
-	current := c.head.next
-	for current != c.tail {
-		count++
-		current = current.next
+	item, exists := c.items[key]
+	if !exists {
+		return false
 	}
-	return count
+	if item.Pinned {
+		item.Pinned = false
+		c.pinned--
+		c.pinnedSize -= item.Size
+	}
+	return true
 }
 
 // Len returns the number of items currently in the cache
@@ -533,13 +783,13 @@ func (c *cache[K, V]) Clear() {
 
 	// Clear all maps and reset size
 	c.items = make(map[K]*cacheItem[K, V])
-	c.expirationMap = make(map[K]*expirationEntry[K])
-	c.expirationQueue = make([]*expirationEntry[K], 0)
+	c.expirations.reset()
 	c.sizeBytes = 0
+	c.pinned = 0
+	c.pinnedSize = 0
 
-	// Reset doubly-linked list
-	c.head.next = c.tail
-	c.tail.prev = c.head
+	// Reset eviction policy state
+	c.policy.Reset()
 }
 
 // Close stops the background cleanup goroutine and releases resources
@@ -548,9 +798,13 @@ func (c *cache[K, V]) Close() {
 	if c.cleanupTicker != nil {
 		c.cleanupTicker.Stop()
 	}
+	if c.expiryTimer != nil {
+		c.expiryTimer.Stop()
+	}
 }
 
-// CleanupExpired manually triggers cleanup of expired items
-func (c *cache[K, V]) CleanupExpired() {
-	c.cleanupExpiredItems()
+// CleanupExpired manually triggers cleanup of expired items and returns
+// how many were removed
+func (c *cache[K, V]) CleanupExpired() int {
+	return c.cleanupExpiredItems()
 }
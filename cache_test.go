@@ -561,3 +561,245 @@ func TestCacheClose(t *testing.T) {
 		t.Errorf("Existing items should still be accessible after close")
 	}
 }
+
+// TestPinnedItemsSurviveEviction tests that pinned items are skipped by eviction
+func TestPinnedItemsSurviveEviction(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, int](&Config{MaxItems: &maxItems})
+
+	values := []int{0, 1, 2}
+	for i := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &values[i])
+	}
+	if !cache.Pin("item0") {
+		t.Fatalf("Pin should succeed for an existing key")
+	}
+	if cache.Pin("missing") {
+		t.Errorf("Pin should report false for a missing key")
+	}
+
+	for i := 3; i < 6; i++ {
+		value := i
+		if err := cache.Set(fmt.Sprintf("item%d", i), &value); err != nil {
+			t.Fatalf("Set should succeed while unpinned items can be evicted: %v", err)
+		}
+	}
+
+	if _, found := cache.Get("item0"); !found {
+		t.Errorf("Pinned item0 should not be evicted")
+	}
+	if cache.Len() != 3 {
+		t.Errorf("Cache should hold 3 items, got %d", cache.Len())
+	}
+}
+
+// TestSetReportsCacheFullOfPinnedItems tests that Set fails instead of overshooting
+func TestSetReportsCacheFullOfPinnedItems(t *testing.T) {
+	maxItems := int64(2)
+	cache := New[string, int](&Config{MaxItems: &maxItems})
+
+	one, two, three := 1, 2, 3
+	cache.Set("a", &one)
+	cache.Set("b", &two)
+	cache.Pin("a")
+	cache.Pin("b")
+
+	if err := cache.Set("c", &three); err != ErrCacheFull {
+		t.Errorf("Expected ErrCacheFull, got %v", err)
+	}
+	if _, found := cache.Get("c"); found {
+		t.Errorf("c should not have been stored")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Cache should still hold 2 items, got %d", cache.Len())
+	}
+
+	// Updating a pinned item in place is still allowed
+	if err := cache.Set("a", &three); err != nil {
+		t.Errorf("Updating a pinned item should succeed: %v", err)
+	}
+
+	// After unpinning, the item can be evicted again
+	cache.Unpin("b")
+	if err := cache.Set("c", &three); err != nil {
+		t.Errorf("Set should succeed after Unpin: %v", err)
+	}
+	if _, found := cache.Get("b"); found {
+		t.Errorf("b should have been evicted after Unpin")
+	}
+}
+
+// TestCacheFullEvictsNothing tests that a write blocked by pinned items
+// fails before any unpinned item is evicted
+func TestCacheFullEvictsNothing(t *testing.T) {
+	maxSize := int64(300)
+	cache := New(&Config{Size: &maxSize},
+		WithWeigher(func(key string, value int64) int64 { return value }))
+
+	pinned, small, big := int64(150), int64(50), int64(200)
+	cache.Set("pinned", &pinned)
+	cache.Pin("pinned")
+	cache.Set("a", &small)
+	cache.Set("b", &small)
+
+	if err := cache.Set("big", &big); err != ErrCacheFull {
+		t.Errorf("Expected ErrCacheFull, got %v", err)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("A rejected write should not evict anything, got %d items", cache.Len())
+	}
+
+	// Growing the pinned item itself is measured against the other pins only
+	if err := cache.Set("pinned", &big); err != nil {
+		t.Errorf("Growing a pinned item within the limit should succeed: %v", err)
+	}
+}
+
+// TestWeigherControlsSizeLimit tests that WithWeigher replaces size estimation
+func TestWeigherControlsSizeLimit(t *testing.T) {
+	maxSize := int64(10)
+	cache := New(&Config{Size: &maxSize},
+		WithWeigher(func(key string, value []string) int64 { return int64(len(value)) }))
+
+	three := []string{"a", "b", "c"}
+	four := []string{"a", "b", "c", "d"}
+	cache.Set("three", &three)
+	cache.Set("four", &four)
+	if cache.Len() != 2 {
+		t.Fatalf("7 units should fit within 10, got %d items", cache.Len())
+	}
+
+	// Growing an existing item also goes through the weigher
+	five := []string{"a", "b", "c", "d", "e"}
+	cache.Set("four", &five)
+	if cache.Len() != 2 {
+		t.Errorf("8 units should still fit within 10, got %d items", cache.Len())
+	}
+
+	cache.Set("more", &four)
+	if _, found := cache.Get("three"); found {
+		t.Errorf("three should have been evicted to stay within 10 units")
+	}
+}
+
+// sizedPayload reports its own size through the Sizer interface
+type sizedPayload struct {
+	Data []byte
+}
+
+func (p sizedPayload) CacheSize() int64 { return int64(len(p.Data)) }
+
+// sizedRecord implements Sizer with a pointer receiver
+type sizedRecord struct {
+	Fields map[string]string
+}
+
+func (r *sizedRecord) CacheSize() int64 { return int64(100 * len(r.Fields)) }
+
+// TestSizerValues tests that values implementing Sizer report their own size
+func TestSizerValues(t *testing.T) {
+	maxSize := int64(2000)
+	payloads := New[string, sizedPayload](&Config{Size: &maxSize})
+
+	small := sizedPayload{Data: make([]byte, 100)}
+	large := sizedPayload{Data: make([]byte, 1500)}
+	payloads.Set("small", &small)
+	payloads.Set("large", &large)
+	if payloads.Len() != 2 {
+		t.Fatalf("Both payloads should fit, got %d items", payloads.Len())
+	}
+
+	// The reflection estimate of a sizedPayload is only a slice header, so
+	// this eviction only happens if CacheSize is used
+	payloads.Set("large2", &large)
+	if _, found := payloads.Get("small"); found {
+		t.Errorf("small should have been evicted once CacheSize pushed the cache over its limit")
+	}
+
+	records := New[string, sizedRecord](nil).(*cache[string, sizedRecord])
+	record := sizedRecord{Fields: map[string]string{"a": "1", "b": "2"}}
+	if got := records.fastCalculateItemSize("r", record); got < 200 {
+		t.Errorf("Pointer-receiver CacheSize should be used, got size %d", got)
+	}
+}
+
+// TestNilSizerPointer tests that a nil pointer value whose type implements
+// Sizer is stored without calling CacheSize
+func TestNilSizerPointer(t *testing.T) {
+	records := New[string, *sizedRecord](nil)
+
+	if err := records.Set("nil", nil); err != nil {
+		t.Fatalf("Set with a nil value should succeed: %v", err)
+	}
+	if val, found := records.Get("nil"); !found || val != nil {
+		t.Errorf("Expected the nil value to be stored and retrieved")
+	}
+
+	var nilRecord *sizedRecord
+	if err := records.Set("nil-record", &nilRecord); err != nil {
+		t.Fatalf("Set with a nil pointer should succeed: %v", err)
+	}
+
+	record := &sizedRecord{Fields: map[string]string{"a": "1"}}
+	if got := records.(*cache[string, *sizedRecord]).fastCalculateItemSize("record", record); got < 100 {
+		t.Errorf("Non-nil pointer should report CacheSize, got size %d", got)
+	}
+}
+
+// TestSlidingTTLExtendsOnRead tests that reads keep a sliding entry alive
+func TestSlidingTTLExtendsOnRead(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock})
+	defer cache.Close()
+	ttl := time.Minute
+
+	value := "session"
+	cache.SetWithSlidingTTL("sliding", &value, ttl)
+	cache.SetWithTTL("fixed", &value, ttl)
+
+	// Keep reading the sliding entry past its original TTL
+	for i := 0; i < 4; i++ {
+		clock.Advance(30 * time.Second)
+		if _, found := cache.Get("sliding"); !found {
+			t.Fatalf("Sliding entry should stay alive while it is read (read %d)", i)
+		}
+	}
+
+	if _, found := cache.Get("fixed"); found {
+		t.Errorf("Fixed TTL entry should have expired")
+	}
+	if remaining, _ := cache.TTL("sliding"); remaining != ttl {
+		t.Errorf("A read should restart the full TTL, got %v remaining", remaining)
+	}
+
+	// Without reads the sliding entry expires
+	clock.Advance(ttl)
+	if _, found := cache.Get("sliding"); found {
+		t.Errorf("Sliding entry should expire once reads stop")
+	}
+}
+
+// TestSlidingTTLMaxLifetime tests that MaxLifetime caps sliding renewals
+func TestSlidingTTLMaxLifetime(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock, SlidingTTL: true, MaxLifetime: 100 * time.Second})
+	defer cache.Close()
+
+	value := "session"
+	cache.SetWithTTL("capped", &value, 50*time.Second)
+
+	for elapsed := 20 * time.Second; elapsed < 100*time.Second; elapsed += 20 * time.Second {
+		clock.Advance(20 * time.Second)
+		if _, found := cache.Get("capped"); !found {
+			t.Fatalf("Entry should be alive before its maximum lifetime (%v)", elapsed)
+		}
+	}
+	if remaining, _ := cache.TTL("capped"); remaining != 20*time.Second {
+		t.Errorf("Renewals should be capped at the maximum lifetime, got %v remaining", remaining)
+	}
+
+	clock.Advance(20 * time.Second)
+	if _, found := cache.Get("capped"); found {
+		t.Errorf("Entry should expire at its maximum lifetime despite reads")
+	}
+}
@@ -0,0 +1,195 @@
+package goinmemcache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time and the timers that drive background
+// expiration. The cache uses the time package by default; FakeClock lets
+// tests control time instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker delivers the time at intervals, like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer delivers the time once after a duration, like time.Timer. Reset
+// and Stop discard a value that has not been received yet.
+type Timer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time        { return t.t.C }
+func (t systemTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+func (t systemTimer) Stop() bool                 { return t.t.Stop() }
+
+// FakeClock is a Clock whose time only moves when Advance is called.
+// Timers and tickers created from it fire during Advance. It is safe for
+// concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer // active timers and tickers
+}
+
+// NewFakeClock returns a FakeClock set to now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the fake time forward by d and fires every timer and
+// ticker that is due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fireDue()
+}
+
+// NewTicker returns a Ticker that fires every d of fake time
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("goinmemcache: non-positive interval for NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1), when: c.now.Add(d), period: d, active: true}
+	c.timers = append(c.timers, t)
+	return fakeTicker{t}
+}
+
+// NewTimer returns a Timer that fires after d of fake time
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1), when: c.now.Add(d), active: true}
+	c.timers = append(c.timers, t)
+	c.fireDue()
+	return t
+}
+
+// fireDue delivers the current time to due timers and drops timers that
+// will not fire again; c.mu must be held
+func (c *FakeClock) fireDue() {
+	active := c.timers[:0]
+	for _, t := range c.timers {
+		if !t.when.After(c.now) {
+			select {
+			case t.ch <- c.now:
+			default: // Like time.Ticker, drop ticks the receiver is not keeping up with
+			}
+			if t.period > 0 {
+				for !t.when.After(c.now) {
+					t.when = t.when.Add(t.period)
+				}
+			} else {
+				t.active = false
+				continue
+			}
+		}
+		active = append(active, t)
+	}
+	clear(c.timers[len(active):])
+	c.timers = active
+}
+
+// removeTimer forgets a timer that was stopped; c.mu must be held
+func (c *FakeClock) removeTimer(t *fakeTimer) {
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = slices.Delete(c.timers, i, i+1)
+			return
+		}
+	}
+}
+
+// fakeTimer is a FakeClock timer, or a ticker when period is set
+type fakeTimer struct {
+	clock  *FakeClock
+	ch     chan time.Time
+	when   time.Time
+	period time.Duration // zero for timers
+	active bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	t.drain()
+	t.when = t.clock.now.Add(d)
+	if !wasActive {
+		t.active = true
+		t.clock.timers = append(t.clock.timers, t)
+	}
+	t.clock.fireDue()
+	return wasActive
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := t.active
+	if wasActive {
+		t.active = false
+		t.clock.removeTimer(t)
+	}
+	t.drain()
+	return wasActive
+}
+
+// fakeTicker adapts a periodic fakeTimer to the Ticker interface
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
+
+// drain discards an undelivered value
+func (t *fakeTimer) drain() {
+	select {
+	case <-t.ch:
+	default:
+	}
+}
@@ -0,0 +1,151 @@
+package goinmemcache
+
+import (
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds, for background work triggered by a
+// FakeClock that runs on another goroutine
+func waitFor(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
+
+// TestFakeClockTimers tests that fake timers and tickers fire on Advance
+func TestFakeClockTimers(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := NewFakeClock(start)
+
+	timer := clock.NewTimer(time.Minute)
+	ticker := clock.NewTicker(10 * time.Second)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-timer.C():
+		t.Errorf("Timer should not fire before its duration")
+	default:
+	}
+	select {
+	case now := <-ticker.C():
+		if !now.Equal(start.Add(30 * time.Second)) {
+			t.Errorf("Ticker delivered %v, want the current fake time", now)
+		}
+	default:
+		t.Errorf("Ticker should have fired")
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("Timer should fire once its duration has passed")
+	}
+
+	if timer.Reset(time.Minute) {
+		t.Errorf("Reset should report that the fired timer was inactive")
+	}
+	if !timer.Stop() {
+		t.Errorf("Stop should report that the timer was active")
+	}
+	clock.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Errorf("Stopped timer should not fire")
+	default:
+	}
+
+	if got := clock.Now(); !got.Equal(start.Add(time.Hour + time.Minute)) {
+		t.Errorf("Expected fake time %v, got %v", start.Add(time.Hour+time.Minute), got)
+	}
+}
+
+// TestFakeClockDropsFinishedTimers tests that fired and stopped timers are
+// no longer tracked, while a fired timer can still be Reset
+func TestFakeClockDropsFinishedTimers(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	ticker := clock.NewTicker(time.Second)
+
+	for i := 0; i < 100; i++ {
+		clock.NewTimer(time.Millisecond)
+		clock.NewTimer(time.Hour).Stop()
+		clock.Advance(time.Millisecond)
+	}
+	if n := len(clock.timers); n != 1 {
+		t.Errorf("Only the ticker should be tracked, got %d timers", n)
+	}
+
+	timer := clock.NewTimer(time.Minute)
+	clock.Advance(time.Minute)
+	<-timer.C()
+	timer.Reset(time.Minute)
+	clock.Advance(time.Minute)
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("A fired timer should fire again after Reset")
+	}
+
+	ticker.Stop()
+	if n := len(clock.timers); n != 0 {
+		t.Errorf("Stopped ticker should not be tracked, got %d timers", n)
+	}
+}
+
+// TestTTLWithFakeClock tests expiration without sleeping
+func TestTTLWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock})
+	defer cache.Close()
+
+	value := "value"
+	cache.SetWithTTL("hour", &value, time.Hour)
+	cache.SetWithSlidingTTL("sliding", &value, 10*time.Minute)
+
+	clock.Advance(59 * time.Minute)
+	if _, found := cache.Get("hour"); !found {
+		t.Errorf("Item should be valid before its TTL")
+	}
+	if _, found := cache.Get("sliding"); found {
+		t.Errorf("Sliding item should expire without reads")
+	}
+	if remaining, _ := cache.TTL("hour"); remaining != time.Minute {
+		t.Errorf("Expected exactly one minute remaining, got %v", remaining)
+	}
+
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("hour"); found {
+		t.Errorf("Item should expire exactly at its TTL")
+	}
+	if removed := cache.CleanupExpired(); removed != 2 {
+		t.Errorf("Expected 2 expired items removed, got %d", removed)
+	}
+}
+
+// TestBackgroundCleanupWithFakeClock tests that the scheduler follows the fake clock
+func TestBackgroundCleanupWithFakeClock(t *testing.T) {
+	for _, precise := range []bool{false, true} {
+		clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+		cache := New[string, string](&Config{Clock: clock, PreciseExpiration: precise})
+
+		value := "value"
+		cache.SetWithTTL("key", &value, 30*time.Second)
+
+		if precise {
+			clock.Advance(30 * time.Second)
+		} else {
+			clock.Advance(time.Minute) // default cleanup interval
+		}
+		if !waitFor(t, func() bool { return cache.Len() == 0 }) {
+			t.Errorf("precise=%v: background cleanup should remove the expired item", precise)
+		}
+		cache.Close()
+	}
+}
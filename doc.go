@@ -5,7 +5,8 @@
 //   - Generic types for both keys (comparable) and values (any type)
 //   - Thread-safe concurrent access using sync.RWMutex
 //   - TTL support with automatic expiration
-//   - FIFO eviction based on item count or memory size limits
+//   - Pluggable eviction (LRU by default, FIFO, or a custom EvictionPolicy)
+//     based on item count or memory size limits
 //   - Memory usage tracking and reporting
 //   - Manual cleanup of expired items
 //
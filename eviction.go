@@ -0,0 +1,179 @@
+package goinmemcache
+
+// EvictionMode selects one of the built-in eviction policies
+type EvictionMode int
+
+const (
+	// EvictionLRU evicts the least recently used item (default)
+	EvictionLRU EvictionMode = iota
+	// EvictionFIFO evicts the oldest inserted item, ignoring reads
+	EvictionFIFO
+	// EvictionLFU evicts the least frequently used item, breaking ties by recency
+	EvictionLFU
+	// EvictionTinyLFU admits new items into the main region only when their
+	// estimated frequency beats the item that would be evicted (W-TinyLFU)
+	EvictionTinyLFU
+	// EvictionARC balances recency and frequency with the Adaptive
+	// Replacement Cache algorithm
+	EvictionARC
+	// EvictionSLRU is a segmented LRU that protects keys read more than once
+	// from churn caused by new inserts
+	EvictionSLRU
+	// EvictionClock is CLOCK (second-chance) eviction; reads only set a
+	// reference bit, so Get runs under the read lock
+	EvictionClock
+	// EvictionS3FIFO uses small, main and ghost FIFO queues (S3-FIFO); reads
+	// only bump a counter, so Get runs under the read lock
+	EvictionS3FIFO
+	// EvictionSampledLRU approximates LRU like Redis allkeys-lru: it samples
+	// a few keys per eviction and keeps no ordering list
+	EvictionSampledLRU
+	// EvictionVolatileLRU samples like EvictionSampledLRU but only evicts
+	// keys that have a TTL (Redis volatile-lru)
+	EvictionVolatileLRU
+	// EvictionVolatileTTL evicts the sampled key with the shortest remaining
+	// TTL (Redis volatile-ttl)
+	EvictionVolatileTTL
+	// EvictionRandom evicts a random key (Redis allkeys-random)
+	EvictionRandom
+	// EvictionGDSF is GreedyDual-Size-Frequency: it weighs each item's cost
+	// (see SetWithCost), size and hit count, evicting cheap, large and
+	// rarely used items first
+	EvictionGDSF
+)
+
+// EvictionPolicy decides which key is removed when the cache is over its
+// item or size limit. The cache calls every method while holding its write
+// lock, so implementations do not need their own synchronization, unless
+// they also implement ConcurrentAccessPolicy: then RecordAccess may run on
+// several goroutines at once under the read lock, concurrently with other
+// RecordAccess calls but never with the remaining methods.
+type EvictionPolicy[K comparable] interface {
+	// RecordInsert is called after a new key has been added to the cache
+	RecordInsert(key K)
+	// RecordAccess is called when an existing key is read or overwritten.
+	// See ConcurrentAccessPolicy for when it may run concurrently.
+	RecordAccess(key K)
+	// RecordRemoval is called after a key has left the cache for any reason
+	RecordRemoval(key K)
+	// Victim returns the next key to evict, ignoring keys for which skip
+	// returns true. It reports false when no key can be evicted.
+	Victim(skip func(K) bool) (K, bool)
+	// Reset forgets all tracked keys
+	Reset()
+}
+
+// ConcurrentAccessPolicy is an optional interface for eviction policies
+// whose RecordAccess is safe to call from several goroutines at once. When
+// ConcurrentAccess returns true the cache serves Get under its read lock
+// instead of the write lock, so RecordAccess must synchronize whatever
+// state it changes, for example with atomics.
+type ConcurrentAccessPolicy interface {
+	ConcurrentAccess() bool
+}
+
+// newPolicy returns custom if set, otherwise the eviction policy selected
+// by config. The sampled and GDSF modes inspect cache items directly, so
+// they are bound to the cache.
+func (c *cache[K, V]) newPolicy(config *Config, custom EvictionPolicy[K]) EvictionPolicy[K] {
+	if custom != nil {
+		return custom
+	}
+	switch config.Eviction {
+	case EvictionSampledLRU, EvictionVolatileLRU, EvictionVolatileTTL, EvictionRandom:
+		return newSampledPolicy(c, config.Eviction, config.EvictionSamples)
+	case EvictionGDSF:
+		return newGDSFPolicy(c)
+	}
+	return newEvictionPolicy[K](config)
+}
+
+// newEvictionPolicy builds the key-only eviction policy selected by config
+func newEvictionPolicy[K comparable](config *Config) EvictionPolicy[K] {
+	switch config.Eviction {
+	case EvictionFIFO:
+		return NewFIFOPolicy[K]()
+	case EvictionLFU:
+		return NewLFUPolicy[K]()
+	case EvictionTinyLFU:
+		var capacity int
+		if config.MaxItems != nil {
+			capacity = int(*config.MaxItems)
+		}
+		return NewTinyLFUPolicy[K](capacity)
+	case EvictionARC:
+		return NewARCPolicy[K]()
+	case EvictionSLRU:
+		return NewSLRUPolicy[K](config.ProtectedRatio)
+	case EvictionClock:
+		return NewClockPolicy[K]()
+	case EvictionS3FIFO:
+		return NewS3FIFOPolicy[K]()
+	default:
+		return NewLRUPolicy[K]()
+	}
+}
+
+// listPolicy keeps keys in a doubly-linked list and evicts from the front.
+// With moveOnAccess set it behaves as LRU, otherwise as FIFO.
+type listPolicy[K comparable] struct {
+	list         *keyList[K]
+	nodes        map[K]*listNode[K]
+	moveOnAccess bool
+}
+
+// NewLRUPolicy returns a least-recently-used eviction policy
+func NewLRUPolicy[K comparable]() EvictionPolicy[K] {
+	return &listPolicy[K]{
+		list:         newKeyList[K](),
+		nodes:        make(map[K]*listNode[K]),
+		moveOnAccess: true,
+	}
+}
+
+// NewFIFOPolicy returns a first-in-first-out eviction policy
+func NewFIFOPolicy[K comparable]() EvictionPolicy[K] {
+	return &listPolicy[K]{
+		list:  newKeyList[K](),
+		nodes: make(map[K]*listNode[K]),
+	}
+}
+
+func (p *listPolicy[K]) RecordInsert(key K) {
+	if node, exists := p.nodes[key]; exists {
+		p.list.moveToBack(node)
+		return
+	}
+	node := &listNode[K]{key: key}
+	p.list.pushBack(node)
+	p.nodes[key] = node
+}
+
+func (p *listPolicy[K]) RecordAccess(key K) {
+	if !p.moveOnAccess {
+		return
+	}
+	if node, exists := p.nodes[key]; exists {
+		p.list.moveToBack(node)
+	}
+}
+
+func (p *listPolicy[K]) RecordRemoval(key K) {
+	if node, exists := p.nodes[key]; exists {
+		p.list.remove(node)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *listPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	if node := p.list.firstMatch(skip); node != nil {
+		return node.key, true
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+func (p *listPolicy[K]) Reset() {
+	p.list.reset()
+	p.nodes = make(map[K]*listNode[K])
+}
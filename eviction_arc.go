@@ -0,0 +1,150 @@
+package goinmemcache
+
+// ARC lists
+const (
+	arcT1 uint8 = iota // resident, seen once recently
+	arcT2              // resident, seen at least twice
+	arcB1              // ghost keys evicted from T1
+	arcB2              // ghost keys evicted from T2
+)
+
+// arcEntry locates a key inside one of the ARC lists
+type arcEntry[K comparable] struct {
+	node *listNode[K]
+	list uint8
+}
+
+// arcPolicy implements the Adaptive Replacement Cache. Resident keys live
+// in T1 (recency) or T2 (frequency), and recently evicted keys are kept as
+// ghosts in B1 and B2. Ghost hits shift the target size of T1 toward
+// whichever side would have kept the key. Because limits may be byte based,
+// the adaptive capacity is the number of resident keys when an eviction
+// happens rather than a fixed item count.
+type arcPolicy[K comparable] struct {
+	lists   [4]*keyList[K]
+	entries map[K]*arcEntry[K]
+	target  int // adaptive target size of T1
+
+	// victim is the key last returned by Victim; its removal turns it into a ghost
+	victim    K
+	hasVictim bool
+}
+
+// NewARCPolicy returns an Adaptive Replacement Cache eviction policy
+func NewARCPolicy[K comparable]() EvictionPolicy[K] {
+	p := &arcPolicy[K]{entries: make(map[K]*arcEntry[K])}
+	for i := range p.lists {
+		p.lists[i] = newKeyList[K]()
+	}
+	return p
+}
+
+func (p *arcPolicy[K]) RecordInsert(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		node := &listNode[K]{key: key}
+		p.lists[arcT1].pushBack(node)
+		p.entries[key] = &arcEntry[K]{node: node, list: arcT1}
+		p.trimGhosts(p.capacity())
+		return
+	}
+
+	b1, b2 := p.lists[arcB1].len, p.lists[arcB2].len
+	switch entry.list {
+	case arcB1:
+		// Recency would have kept the key, so grow T1's share
+		p.target = min(p.capacity(), p.target+max(1, b2/b1))
+	case arcB2:
+		// Frequency would have kept the key, so shrink T1's share
+		p.target = max(0, p.target-max(1, b1/b2))
+	}
+	p.move(entry, arcT2)
+	p.trimGhosts(p.capacity())
+}
+
+func (p *arcPolicy[K]) RecordAccess(key K) {
+	if entry, exists := p.entries[key]; exists && (entry.list == arcT1 || entry.list == arcT2) {
+		p.move(entry, arcT2)
+	}
+}
+
+func (p *arcPolicy[K]) RecordRemoval(key K) {
+	entry, exists := p.entries[key]
+	if !exists || (entry.list != arcT1 && entry.list != arcT2) {
+		return
+	}
+
+	if !p.hasVictim || p.victim != key {
+		// Deleted or expired, not evicted: forget the key entirely
+		p.lists[entry.list].remove(entry.node)
+		delete(p.entries, key)
+		return
+	}
+
+	p.hasVictim = false
+	if entry.list == arcT1 {
+		p.move(entry, arcB1)
+	} else {
+		p.move(entry, arcB2)
+	}
+}
+
+func (p *arcPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	t1, t2 := p.lists[arcT1], p.lists[arcT2]
+
+	// Evict from T1 while it is larger than its target, otherwise from T2
+	first, second := t2, t1
+	if t1.len > 0 && (t1.len > p.target || t2.len == 0) {
+		first, second = t1, t2
+	}
+
+	node := first.firstMatch(skip)
+	if node == nil {
+		node = second.firstMatch(skip)
+	}
+	if node == nil {
+		var zeroK K
+		return zeroK, false
+	}
+
+	p.victim, p.hasVictim = node.key, true
+	return node.key, true
+}
+
+func (p *arcPolicy[K]) Reset() {
+	for _, list := range p.lists {
+		list.reset()
+	}
+	p.entries = make(map[K]*arcEntry[K])
+	p.target = 0
+	p.hasVictim = false
+}
+
+// move relinks an entry at the most recent end of the given list
+func (p *arcPolicy[K]) move(entry *arcEntry[K], list uint8) {
+	p.lists[entry.list].remove(entry.node)
+	p.lists[list].pushBack(entry.node)
+	entry.list = list
+}
+
+// capacity is the number of resident keys
+func (p *arcPolicy[K]) capacity() int {
+	return p.lists[arcT1].len + p.lists[arcT2].len
+}
+
+// trimGhosts keeps |T1|+|B1| within c and the whole directory within 2c
+func (p *arcPolicy[K]) trimGhosts(c int) {
+	for p.lists[arcT1].len+p.lists[arcB1].len > c && p.lists[arcB1].len > 0 {
+		p.dropGhost(arcB1)
+	}
+	for p.capacity()+p.lists[arcB1].len+p.lists[arcB2].len > 2*c && p.lists[arcB2].len > 0 {
+		p.dropGhost(arcB2)
+	}
+}
+
+// dropGhost forgets the oldest ghost key in the given list
+func (p *arcPolicy[K]) dropGhost(list uint8) {
+	if node := p.lists[list].popFront(); node != nil {
+		delete(p.entries, node.key)
+	}
+}
@@ -0,0 +1,104 @@
+package goinmemcache
+
+import "sync/atomic"
+
+// clockEntry pairs a key's ring node with its reference bit
+type clockEntry[K comparable] struct {
+	node       *listNode[K]
+	referenced atomic.Bool
+}
+
+// clockPolicy implements CLOCK (second-chance) eviction. Keys sit on a ring
+// in insertion order and a hit only sets the key's reference bit, so reads
+// never relink nodes. At eviction time the hand sweeps the ring, clearing
+// set bits and stopping at the first key whose bit is already clear.
+type clockPolicy[K comparable] struct {
+	ring    *keyList[K]
+	entries map[K]*clockEntry[K]
+	hand    *listNode[K] // next node to inspect, nil means the ring's front
+}
+
+// NewClockPolicy returns a CLOCK (second-chance) eviction policy. Its
+// RecordAccess is safe to call concurrently, so the cache serves Get under
+// a read lock when this policy is used.
+func NewClockPolicy[K comparable]() EvictionPolicy[K] {
+	return &clockPolicy[K]{
+		ring:    newKeyList[K](),
+		entries: make(map[K]*clockEntry[K]),
+	}
+}
+
+// ConcurrentAccess reports that RecordAccess only sets an atomic bit
+func (p *clockPolicy[K]) ConcurrentAccess() bool {
+	return true
+}
+
+func (p *clockPolicy[K]) RecordInsert(key K) {
+	if entry, exists := p.entries[key]; exists {
+		entry.referenced.Store(true)
+		return
+	}
+	node := &listNode[K]{key: key}
+	p.ring.pushBack(node)
+	p.entries[key] = &clockEntry[K]{node: node}
+}
+
+func (p *clockPolicy[K]) RecordAccess(key K) {
+	if entry, exists := p.entries[key]; exists && !entry.referenced.Load() {
+		entry.referenced.Store(true)
+	}
+}
+
+func (p *clockPolicy[K]) RecordRemoval(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+	if p.hand == entry.node {
+		p.hand = p.next(entry.node)
+		if p.hand == entry.node {
+			p.hand = nil // removing the last node
+		}
+	}
+	p.ring.remove(entry.node)
+	delete(p.entries, key)
+}
+
+func (p *clockPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	// Two full turns are enough: the first clears every set bit
+	for i := 0; i < 2*p.ring.len; i++ {
+		node := p.hand
+		if node == nil {
+			node = p.ring.front()
+		}
+		p.hand = p.next(node)
+
+		if skip != nil && skip(node.key) {
+			continue
+		}
+		entry := p.entries[node.key]
+		if entry.referenced.Load() {
+			entry.referenced.Store(false) // second chance
+			continue
+		}
+		p.hand = node
+		return node.key, true
+	}
+
+	var zeroK K
+	return zeroK, false
+}
+
+func (p *clockPolicy[K]) Reset() {
+	p.ring.reset()
+	p.entries = make(map[K]*clockEntry[K])
+	p.hand = nil
+}
+
+// next returns the node after node on the ring, wrapping at the end
+func (p *clockPolicy[K]) next(node *listNode[K]) *listNode[K] {
+	if node.next == p.ring.tail {
+		return p.ring.front()
+	}
+	return node.next
+}
@@ -0,0 +1,124 @@
+package goinmemcache
+
+import "container/heap"
+
+// gdsfEntry is a key's position in the GDSF priority queue
+type gdsfEntry[K comparable] struct {
+	key      K
+	priority float64
+	freq     int64
+	index    int // index in the heap
+}
+
+// gdsfHeap implements heap.Interface ordered by ascending priority
+type gdsfHeap[K comparable] []*gdsfEntry[K]
+
+func (h gdsfHeap[K]) Len() int           { return len(h) }
+func (h gdsfHeap[K]) Less(i, j int) bool { return h[i].priority < h[j].priority }
+func (h gdsfHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *gdsfHeap[K]) Push(x interface{}) {
+	entry := x.(*gdsfEntry[K])
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *gdsfHeap[K]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.index = -1
+	*h = old[0 : n-1]
+	return entry
+}
+
+// gdsfPolicy implements GreedyDual-Size-Frequency. Every key has the
+// priority L + freq*cost/size, where cost is the value passed to
+// SetWithCost (1 otherwise), size is the item's byte size and L is an
+// inflation value raised to the priority of each evicted key. The key with
+// the lowest priority is evicted, so cheap, large and rarely used entries
+// go before expensive, small and hot ones, and L ages out keys that were
+// hot long ago.
+type gdsfPolicy[K comparable, V any] struct {
+	c         *cache[K, V]
+	queue     gdsfHeap[K]
+	entries   map[K]*gdsfEntry[K]
+	inflation float64
+}
+
+func newGDSFPolicy[K comparable, V any](c *cache[K, V]) EvictionPolicy[K] {
+	return &gdsfPolicy[K, V]{
+		c:       c,
+		entries: make(map[K]*gdsfEntry[K]),
+	}
+}
+
+func (p *gdsfPolicy[K, V]) RecordInsert(key K) {
+	if _, exists := p.entries[key]; exists {
+		p.RecordAccess(key)
+		return
+	}
+	entry := &gdsfEntry[K]{key: key, freq: 1}
+	entry.priority = p.priority(entry)
+	heap.Push(&p.queue, entry)
+	p.entries[key] = entry
+}
+
+func (p *gdsfPolicy[K, V]) RecordAccess(key K) {
+	if entry, exists := p.entries[key]; exists {
+		entry.freq++
+		entry.priority = p.priority(entry)
+		heap.Fix(&p.queue, entry.index)
+	}
+}
+
+func (p *gdsfPolicy[K, V]) RecordRemoval(key K) {
+	if entry, exists := p.entries[key]; exists {
+		heap.Remove(&p.queue, entry.index)
+		delete(p.entries, key)
+	}
+}
+
+func (p *gdsfPolicy[K, V]) Victim(skip func(K) bool) (K, bool) {
+	// Pop skipped keys aside until a candidate is found, then restore them
+	var skipped []*gdsfEntry[K]
+	var victim *gdsfEntry[K]
+	for p.queue.Len() > 0 {
+		entry := p.queue[0]
+		if skip == nil || !skip(entry.key) {
+			victim = entry
+			break
+		}
+		skipped = append(skipped, heap.Pop(&p.queue).(*gdsfEntry[K]))
+	}
+	for _, entry := range skipped {
+		heap.Push(&p.queue, entry)
+	}
+
+	if victim == nil {
+		var zeroK K
+		return zeroK, false
+	}
+	p.inflation = victim.priority
+	return victim.key, true
+}
+
+func (p *gdsfPolicy[K, V]) Reset() {
+	p.queue = nil
+	p.entries = make(map[K]*gdsfEntry[K])
+	p.inflation = 0
+}
+
+// priority computes L + freq*cost/size for an entry
+func (p *gdsfPolicy[K, V]) priority(entry *gdsfEntry[K]) float64 {
+	cost, size := int64(defaultItemCost), int64(1)
+	if item, exists := p.c.items[entry.key]; exists {
+		cost = item.Cost
+		size = max(1, item.Size)
+	}
+	return p.inflation + float64(entry.freq)*float64(cost)/float64(size)
+}
@@ -0,0 +1,116 @@
+package goinmemcache
+
+// lfuBucket groups all keys that share the same access frequency. Keys
+// inside a bucket are kept in LRU order so ties evict the least recently
+// used key first.
+type lfuBucket[K comparable] struct {
+	freq uint64
+	keys *keyList[K]
+	prev *lfuBucket[K]
+	next *lfuBucket[K]
+}
+
+// lfuEntry locates a key inside its frequency bucket
+type lfuEntry[K comparable] struct {
+	node   *listNode[K]
+	bucket *lfuBucket[K]
+}
+
+// lfuPolicy is an O(1) least-frequently-used policy built from a
+// doubly-linked list of frequency buckets ordered by ascending frequency
+type lfuPolicy[K comparable] struct {
+	head    *lfuBucket[K] // dummy bucket before the lowest frequency
+	tail    *lfuBucket[K] // dummy bucket after the highest frequency
+	entries map[K]*lfuEntry[K]
+}
+
+// NewLFUPolicy returns a least-frequently-used eviction policy
+func NewLFUPolicy[K comparable]() EvictionPolicy[K] {
+	p := &lfuPolicy[K]{entries: make(map[K]*lfuEntry[K])}
+	p.Reset()
+	return p
+}
+
+func (p *lfuPolicy[K]) RecordInsert(key K) {
+	if _, exists := p.entries[key]; exists {
+		p.RecordAccess(key)
+		return
+	}
+
+	bucket := p.head.next
+	if bucket == p.tail || bucket.freq != 1 {
+		bucket = p.insertBucketAfter(p.head, 1)
+	}
+	node := &listNode[K]{key: key}
+	bucket.keys.pushBack(node)
+	p.entries[key] = &lfuEntry[K]{node: node, bucket: bucket}
+}
+
+func (p *lfuPolicy[K]) RecordAccess(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+
+	current := entry.bucket
+	next := current.next
+	if next == p.tail || next.freq != current.freq+1 {
+		next = p.insertBucketAfter(current, current.freq+1)
+	}
+
+	current.keys.remove(entry.node)
+	next.keys.pushBack(entry.node)
+	entry.bucket = next
+	p.removeBucketIfEmpty(current)
+}
+
+func (p *lfuPolicy[K]) RecordRemoval(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+
+	entry.bucket.keys.remove(entry.node)
+	p.removeBucketIfEmpty(entry.bucket)
+	delete(p.entries, key)
+}
+
+func (p *lfuPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	for bucket := p.head.next; bucket != p.tail; bucket = bucket.next {
+		if node := bucket.keys.firstMatch(skip); node != nil {
+			return node.key, true
+		}
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+func (p *lfuPolicy[K]) Reset() {
+	p.head = &lfuBucket[K]{}
+	p.tail = &lfuBucket[K]{}
+	p.head.next = p.tail
+	p.tail.prev = p.head
+	p.entries = make(map[K]*lfuEntry[K])
+}
+
+// insertBucketAfter creates an empty bucket for freq right after prev
+func (p *lfuPolicy[K]) insertBucketAfter(prev *lfuBucket[K], freq uint64) *lfuBucket[K] {
+	bucket := &lfuBucket[K]{
+		freq: freq,
+		keys: newKeyList[K](),
+		prev: prev,
+		next: prev.next,
+	}
+	prev.next.prev = bucket
+	prev.next = bucket
+	return bucket
+}
+
+// removeBucketIfEmpty unlinks a bucket once its last key has left
+func (p *lfuPolicy[K]) removeBucketIfEmpty(bucket *lfuBucket[K]) {
+	if bucket.keys.len > 0 {
+		return
+	}
+	bucket.prev.next = bucket.next
+	bucket.next.prev = bucket.prev
+}
@@ -0,0 +1,179 @@
+package goinmemcache
+
+import "sync/atomic"
+
+const (
+	s3FIFOSmallPercent = 10 // share of resident keys kept in the small queue
+	s3FIFOMaxFreq      = 3  // saturation point of the per-entry counter
+)
+
+// S3-FIFO queues
+const (
+	s3Small uint8 = iota
+	s3Main
+	s3Ghost
+)
+
+// s3FIFOEntry locates a key in one of the queues and counts its hits
+type s3FIFOEntry[K comparable] struct {
+	node  *listNode[K]
+	queue uint8
+	freq  atomic.Uint32
+}
+
+// s3FIFOPolicy implements S3-FIFO. New keys enter a small FIFO; keys that
+// were hit while in it move on to the main FIFO, the rest are evicted and
+// remembered in a ghost FIFO so a quick return goes straight to main. The
+// main FIFO reinserts keys with a non-zero counter, decrementing it, which
+// approximates LRU without touching the queues on reads.
+type s3FIFOPolicy[K comparable] struct {
+	queues  [3]*keyList[K]
+	entries map[K]*s3FIFOEntry[K]
+
+	// victim is the key last returned by Victim from the small queue; its
+	// removal leaves a ghost behind
+	victim    K
+	hasVictim bool
+}
+
+// NewS3FIFOPolicy returns an S3-FIFO eviction policy. Its RecordAccess is
+// safe to call concurrently, so the cache serves Get under a read lock.
+func NewS3FIFOPolicy[K comparable]() EvictionPolicy[K] {
+	p := &s3FIFOPolicy[K]{entries: make(map[K]*s3FIFOEntry[K])}
+	for i := range p.queues {
+		p.queues[i] = newKeyList[K]()
+	}
+	return p
+}
+
+// ConcurrentAccess reports that RecordAccess only bumps an atomic counter
+func (p *s3FIFOPolicy[K]) ConcurrentAccess() bool {
+	return true
+}
+
+func (p *s3FIFOPolicy[K]) RecordInsert(key K) {
+	entry, exists := p.entries[key]
+	switch {
+	case !exists:
+		node := &listNode[K]{key: key}
+		p.queues[s3Small].pushBack(node)
+		p.entries[key] = &s3FIFOEntry[K]{node: node, queue: s3Small}
+	case entry.queue == s3Ghost:
+		// Seen recently, admit straight into main
+		entry.freq.Store(0)
+		p.move(entry, s3Main)
+	default:
+		p.RecordAccess(key)
+	}
+	p.trimGhosts()
+}
+
+func (p *s3FIFOPolicy[K]) RecordAccess(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+	for {
+		freq := entry.freq.Load()
+		if freq >= s3FIFOMaxFreq || entry.freq.CompareAndSwap(freq, freq+1) {
+			return
+		}
+	}
+}
+
+func (p *s3FIFOPolicy[K]) RecordRemoval(key K) {
+	entry, exists := p.entries[key]
+	if !exists || entry.queue == s3Ghost {
+		return
+	}
+
+	if p.hasVictim && p.victim == key && entry.queue == s3Small {
+		p.hasVictim = false
+		p.move(entry, s3Ghost)
+		return
+	}
+	p.queues[entry.queue].remove(entry.node)
+	delete(p.entries, key)
+}
+
+func (p *s3FIFOPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	small, main := p.queues[s3Small], p.queues[s3Main]
+	smallTarget := max(1, (small.len+main.len)*s3FIFOSmallPercent/100)
+
+	if small.len >= smallTarget || main.len == 0 {
+		if key, ok := p.evictSmall(skip); ok {
+			return key, true
+		}
+	}
+	if key, ok := p.evictMain(skip); ok {
+		return key, true
+	}
+	return p.evictSmall(skip)
+}
+
+func (p *s3FIFOPolicy[K]) Reset() {
+	for _, queue := range p.queues {
+		queue.reset()
+	}
+	p.entries = make(map[K]*s3FIFOEntry[K])
+	p.hasVictim = false
+}
+
+// evictSmall walks the small queue, promoting keys that were hit into main,
+// and returns the first key that was not
+func (p *s3FIFOPolicy[K]) evictSmall(skip func(K) bool) (K, bool) {
+	small := p.queues[s3Small]
+	for steps := small.len; steps > 0; steps-- {
+		node := small.front()
+		entry := p.entries[node.key]
+		switch {
+		case skip != nil && skip(node.key):
+			small.moveToBack(node)
+		case entry.freq.Load() > 0:
+			entry.freq.Store(0)
+			p.move(entry, s3Main)
+		default:
+			p.victim, p.hasVictim = node.key, true
+			return node.key, true
+		}
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+// evictMain walks the main queue, reinserting keys with a non-zero counter,
+// and returns the first key whose counter is zero
+func (p *s3FIFOPolicy[K]) evictMain(skip func(K) bool) (K, bool) {
+	main := p.queues[s3Main]
+	for steps := main.len * (s3FIFOMaxFreq + 1); steps > 0; steps-- {
+		node := main.front()
+		entry := p.entries[node.key]
+		switch {
+		case skip != nil && skip(node.key):
+			main.moveToBack(node)
+		case entry.freq.Load() > 0:
+			entry.freq.Add(^uint32(0)) // decrement
+			main.moveToBack(node)
+		default:
+			return node.key, true
+		}
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+// move relinks an entry at the back of the given queue
+func (p *s3FIFOPolicy[K]) move(entry *s3FIFOEntry[K], queue uint8) {
+	p.queues[entry.queue].remove(entry.node)
+	p.queues[queue].pushBack(entry.node)
+	entry.queue = queue
+}
+
+// trimGhosts bounds the ghost queue by the size of the main queue
+func (p *s3FIFOPolicy[K]) trimGhosts() {
+	ghost := p.queues[s3Ghost]
+	for ghost.len > max(1, p.queues[s3Main].len) {
+		node := ghost.popFront()
+		delete(p.entries, node.key)
+	}
+}
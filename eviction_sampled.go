@@ -0,0 +1,167 @@
+package goinmemcache
+
+const (
+	defaultEvictionSamples = 5
+	evictionPoolSize       = 16
+)
+
+// poolEntry is an eviction candidate remembered across sampling rounds
+type poolEntry[K comparable] struct {
+	key   K
+	score int64 // higher is a better victim
+}
+
+// sampledPolicy approximates LRU or TTL ordering the way Redis does for
+// maxmemory-policy: it keeps no per-key structure, and at eviction time it
+// samples a few keys from the cache, merges them into a small pool of the
+// best candidates seen so far and evicts the best one. Go map iteration
+// starts at a random position, which is what makes the samples random.
+type sampledPolicy[K comparable, V any] struct {
+	c       *cache[K, V]
+	mode    EvictionMode
+	samples int
+	pool    []poolEntry[K] // sorted by descending score
+}
+
+func newSampledPolicy[K comparable, V any](c *cache[K, V], mode EvictionMode, samples int) EvictionPolicy[K] {
+	if samples <= 0 {
+		samples = defaultEvictionSamples
+	}
+	return &sampledPolicy[K, V]{
+		c:       c,
+		mode:    mode,
+		samples: samples,
+		pool:    make([]poolEntry[K], 0, evictionPoolSize),
+	}
+}
+
+// ConcurrentAccess reports that RecordAccess only stores an atomic timestamp
+func (p *sampledPolicy[K, V]) ConcurrentAccess() bool {
+	return true
+}
+
+func (p *sampledPolicy[K, V]) RecordInsert(key K) {
+	p.RecordAccess(key)
+}
+
+func (p *sampledPolicy[K, V]) RecordAccess(key K) {
+	if p.mode == EvictionRandom {
+		return // access time is never consulted
+	}
+	if item, exists := p.c.items[key]; exists {
+		item.LastAccess.Store(p.c.clock.Now().UnixNano())
+	}
+}
+
+// RecordRemoval is a no-op: stale pool entries are dropped when popped
+func (p *sampledPolicy[K, V]) RecordRemoval(key K) {}
+
+func (p *sampledPolicy[K, V]) Victim(skip func(K) bool) (K, bool) {
+	if p.mode == EvictionRandom {
+		for key := range p.c.items {
+			if skip == nil || !skip(key) {
+				return key, true
+			}
+		}
+		var zeroK K
+		return zeroK, false
+	}
+
+	for {
+		sampled := p.populate(skip)
+		for len(p.pool) > 0 {
+			candidate := p.pool[0]
+			copy(p.pool, p.pool[1:])
+			p.pool = p.pool[:len(p.pool)-1]
+			if item, exists := p.c.items[candidate.key]; exists && p.eligible(item) && (skip == nil || !skip(candidate.key)) {
+				return candidate.key, true
+			}
+		}
+		if sampled == 0 {
+			var zeroK K
+			return zeroK, false
+		}
+		// Stale entries crowded the sampled keys out of the pool; the pool
+		// is empty now, so the next round keeps them
+	}
+}
+
+func (p *sampledPolicy[K, V]) Reset() {
+	p.pool = p.pool[:0]
+}
+
+// populate samples keys and merges them into the eviction pool. The
+// volatile modes sample from the keys that have an expiration entry. Keys
+// that are skipped or not eligible do not count toward the sample, so when
+// few keys can be evicted this degrades into a full scan rather than
+// missing them. It returns how many evictable keys were sampled.
+func (p *sampledPolicy[K, V]) populate(skip func(K) bool) int {
+	now := p.c.clock.Now().UnixNano()
+	sampled := 0
+	if p.mode == EvictionVolatileLRU || p.mode == EvictionVolatileTTL {
+		p.c.expirations.each(func(key K) bool {
+			if p.consider(key, skip, now) {
+				sampled++
+			}
+			return sampled < p.samples
+		})
+		return sampled
+	}
+	for key := range p.c.items {
+		if p.consider(key, skip, now) {
+			sampled++
+			if sampled >= p.samples {
+				break
+			}
+		}
+	}
+	return sampled
+}
+
+// consider scores a sampled key and inserts it into the pool if it beats
+// the worst candidate or the pool has room. It reports whether the key
+// could be evicted.
+func (p *sampledPolicy[K, V]) consider(key K, skip func(K) bool, now int64) bool {
+	if skip != nil && skip(key) {
+		return false
+	}
+	item, exists := p.c.items[key]
+	if !exists || !p.eligible(item) {
+		return false
+	}
+	for _, entry := range p.pool {
+		if entry.key == key {
+			return true
+		}
+	}
+
+	var score int64
+	if p.mode == EvictionVolatileTTL {
+		expireTime, _ := p.c.expireTime(item)
+		score = -expireTime.UnixNano() // sooner expiry scores higher
+	} else {
+		score = now - item.LastAccess.Load() // idle time
+	}
+
+	pos := len(p.pool)
+	for pos > 0 && p.pool[pos-1].score < score {
+		pos--
+	}
+	if pos >= evictionPoolSize {
+		return true
+	}
+	if len(p.pool) < evictionPoolSize {
+		p.pool = append(p.pool, poolEntry[K]{})
+	}
+	copy(p.pool[pos+1:], p.pool[pos:])
+	p.pool[pos] = poolEntry[K]{key: key, score: score}
+	return true
+}
+
+// eligible reports whether an item may be evicted in the current mode
+func (p *sampledPolicy[K, V]) eligible(item *cacheItem[K, V]) bool {
+	if p.mode == EvictionVolatileLRU || p.mode == EvictionVolatileTTL {
+		return item.TTL != nil
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package goinmemcache
+
+// defaultProtectedRatio is the share of keys kept in the protected segment
+const defaultProtectedRatio = 0.8
+
+// slruEntry locates a key in the probation or protected segment
+type slruEntry[K comparable] struct {
+	node      *listNode[K]
+	protected bool
+}
+
+// slruPolicy implements segmented LRU. New keys enter the probation
+// segment, a hit promotes a key to the protected segment, and keys pushed
+// out of the protected segment are demoted back to probation. Victims are
+// taken from probation first, so one-time keys cannot flush keys that
+// have been read more than once.
+type slruPolicy[K comparable] struct {
+	probation      *keyList[K]
+	protected      *keyList[K]
+	entries        map[K]*slruEntry[K]
+	protectedRatio float64
+}
+
+// NewSLRUPolicy returns a segmented LRU eviction policy that keeps up to
+// protectedRatio of the tracked keys in the protected segment. A ratio
+// outside (0, 1) selects the default of 0.8.
+func NewSLRUPolicy[K comparable](protectedRatio float64) EvictionPolicy[K] {
+	if protectedRatio <= 0 || protectedRatio >= 1 {
+		protectedRatio = defaultProtectedRatio
+	}
+	return &slruPolicy[K]{
+		probation:      newKeyList[K](),
+		protected:      newKeyList[K](),
+		entries:        make(map[K]*slruEntry[K]),
+		protectedRatio: protectedRatio,
+	}
+}
+
+func (p *slruPolicy[K]) RecordInsert(key K) {
+	if _, exists := p.entries[key]; exists {
+		p.RecordAccess(key)
+		return
+	}
+	node := &listNode[K]{key: key}
+	p.probation.pushBack(node)
+	p.entries[key] = &slruEntry[K]{node: node}
+}
+
+func (p *slruPolicy[K]) RecordAccess(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+	if entry.protected {
+		p.protected.moveToBack(entry.node)
+		return
+	}
+
+	// Promote from probation to protected
+	p.probation.remove(entry.node)
+	p.protected.pushBack(entry.node)
+	entry.protected = true
+
+	// Demote the oldest protected keys back to probation once the
+	// protected segment is over its share
+	limit := max(1, int(float64(p.probation.len+p.protected.len)*p.protectedRatio))
+	for p.protected.len > limit {
+		demoted := p.protected.popFront()
+		p.probation.pushBack(demoted)
+		p.entries[demoted.key].protected = false
+	}
+}
+
+func (p *slruPolicy[K]) RecordRemoval(key K) {
+	entry, exists := p.entries[key]
+	if !exists {
+		return
+	}
+	if entry.protected {
+		p.protected.remove(entry.node)
+	} else {
+		p.probation.remove(entry.node)
+	}
+	delete(p.entries, key)
+}
+
+func (p *slruPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	if node := p.probation.firstMatch(skip); node != nil {
+		return node.key, true
+	}
+	if node := p.protected.firstMatch(skip); node != nil {
+		return node.key, true
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+func (p *slruPolicy[K]) Reset() {
+	p.probation.reset()
+	p.protected.reset()
+	p.entries = make(map[K]*slruEntry[K])
+}
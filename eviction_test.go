@@ -0,0 +1,660 @@
+package goinmemcache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFIFOEvictionIgnoresReads tests that FIFO evicts by insertion order even after reads
+func TestFIFOEvictionIgnoresReads(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionFIFO})
+
+	values := []string{"first", "second", "third"}
+	for i, val := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &val)
+	}
+
+	// Reading item0 must not protect it under FIFO
+	cache.Get("item0")
+
+	fourth := "fourth"
+	cache.Set("item3", &fourth)
+
+	if _, found := cache.Get("item0"); found {
+		t.Errorf("item0 should have been evicted (was inserted first)")
+	}
+	for _, key := range []string{"item1", "item2", "item3"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("%s should remain", key)
+		}
+	}
+}
+
+// TestUpdateDoesNotEvict tests that overwriting a key at the item limit keeps other items
+func TestUpdateDoesNotEvict(t *testing.T) {
+	maxItems := int64(2)
+	cache := New[string, int](&Config{MaxItems: &maxItems})
+
+	one, two, three := 1, 2, 3
+	cache.Set("a", &one)
+	cache.Set("b", &two)
+	cache.Set("a", &three)
+
+	if cache.Len() != 2 {
+		t.Errorf("Cache should still hold 2 items after update, got %d", cache.Len())
+	}
+	if val, found := cache.Get("a"); !found || *val != 3 {
+		t.Errorf("Expected updated value 3 for a, got %v", val)
+	}
+	if _, found := cache.Get("b"); !found {
+		t.Errorf("b should not be evicted by an update of a")
+	}
+}
+
+// recordingPolicy wraps the LRU policy and counts calls
+type recordingPolicy struct {
+	EvictionPolicy[string]
+	inserts, removals int
+}
+
+func (p *recordingPolicy) RecordInsert(key string) {
+	p.inserts++
+	p.EvictionPolicy.RecordInsert(key)
+}
+
+func (p *recordingPolicy) RecordRemoval(key string) {
+	p.removals++
+	p.EvictionPolicy.RecordRemoval(key)
+}
+
+// TestCustomEvictionPolicy tests that a policy supplied through WithEvictionPolicy is used
+func TestCustomEvictionPolicy(t *testing.T) {
+	maxItems := int64(2)
+	policy := &recordingPolicy{EvictionPolicy: NewLRUPolicy[string]()}
+	cache := New[string, int](&Config{MaxItems: &maxItems}, WithEvictionPolicy[string, int](policy))
+
+	for i := 0; i < 3; i++ {
+		value := i
+		cache.Set(fmt.Sprintf("key%d", i), &value)
+	}
+	cache.Delete("key2")
+
+	if policy.inserts != 3 {
+		t.Errorf("Expected 3 inserts, got %d", policy.inserts)
+	}
+	if policy.removals != 2 {
+		t.Errorf("Expected 2 removals (one eviction, one delete), got %d", policy.removals)
+	}
+	if _, found := cache.Get("key0"); found {
+		t.Errorf("key0 should have been evicted")
+	}
+}
+
+// TestLFUOrdering tests that LFU evicts the least frequently used item
+func TestLFUOrdering(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionLFU})
+
+	values := []string{"first", "second", "third"}
+	for i, val := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &val)
+	}
+
+	// item0 is read twice and item2 once, item1 is never read
+	cache.Get("item0")
+	cache.Get("item0")
+	cache.Get("item2")
+
+	// Add fourth item - should evict item1 (least frequently used)
+	fourth := "fourth"
+	cache.Set("item3", &fourth)
+
+	if _, found := cache.Get("item1"); found {
+		t.Errorf("item1 should have been evicted (was least frequently used)")
+	}
+	if _, found := cache.Get("item0"); !found {
+		t.Errorf("item0 should remain (was most frequently used)")
+	}
+	if _, found := cache.Get("item2"); !found {
+		t.Errorf("item2 should remain")
+	}
+	if _, found := cache.Get("item3"); !found {
+		t.Errorf("item3 should remain (was just added)")
+	}
+}
+
+// TestLFUTieBreaksByRecency tests that keys with equal frequency are evicted in LRU order
+func TestLFUTieBreaksByRecency(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionLFU})
+
+	values := []string{"first", "second", "third"}
+	for i, val := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &val)
+	}
+
+	// Every key is read once; item1 is read first so it is the least recent
+	cache.Get("item1")
+	cache.Get("item0")
+	cache.Get("item2")
+
+	fourth := "fourth"
+	cache.Set("item3", &fourth)
+
+	if _, found := cache.Get("item1"); found {
+		t.Errorf("item1 should have been evicted (least recently used among equals)")
+	}
+	if cache.Len() != 3 {
+		t.Errorf("Cache should hold 3 items, got %d", cache.Len())
+	}
+}
+
+// TestLFUScanResistance tests that a scan of new keys does not flush a hot key
+func TestLFUScanResistance(t *testing.T) {
+	maxItems := int64(10)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: EvictionLFU})
+
+	hot := -1
+	cache.Set(hot, &hot)
+	for i := 0; i < 5; i++ {
+		cache.Get(hot)
+	}
+
+	for i := 0; i < 100; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+
+	if _, found := cache.Get(hot); !found {
+		t.Errorf("Hot key should survive a scan under LFU")
+	}
+}
+
+// TestTinyLFUAdmission tests that one-hit wonders do not displace frequently used items
+func TestTinyLFUAdmission(t *testing.T) {
+	maxItems := int64(100)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: EvictionTinyLFU})
+
+	// Fill the cache and make the first 50 keys hot
+	for i := 0; i < 100; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 50; i++ {
+			cache.Get(i)
+		}
+	}
+
+	// A scan of keys that are never read again
+	for i := 1000; i < 2000; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+
+	if cache.Len() != 100 {
+		t.Errorf("Cache should hold 100 items, got %d", cache.Len())
+	}
+	hotFound := 0
+	for i := 0; i < 50; i++ {
+		if _, found := cache.Get(i); found {
+			hotFound++
+		}
+	}
+	if hotFound < 45 {
+		t.Errorf("Expected hot keys to survive the scan, only %d of 50 remain", hotFound)
+	}
+}
+
+// TestCountMinSketchEstimate tests frequency estimation, the doorkeeper and aging
+func TestCountMinSketchEstimate(t *testing.T) {
+	sketch := newCountMinSketch[string](64)
+
+	if got := sketch.estimate("a"); got != 0 {
+		t.Errorf("Unseen key should estimate 0, got %d", got)
+	}
+
+	sketch.increment("a")
+	if got := sketch.estimate("a"); got != 1 {
+		t.Errorf("First increment should only reach the doorkeeper, got %d", got)
+	}
+
+	for i := 0; i < 4; i++ {
+		sketch.increment("a")
+	}
+	if got := sketch.estimate("a"); got != 5 {
+		t.Errorf("Expected estimate 5, got %d", got)
+	}
+
+	sketch.age()
+	if got := sketch.estimate("a"); got != 2 {
+		t.Errorf("Aging should halve counters and clear the doorkeeper, got %d", got)
+	}
+}
+
+// TestARCPromotesRepeatedKeys tests that ARC protects keys read twice from a scan
+func TestARCPromotesRepeatedKeys(t *testing.T) {
+	maxItems := int64(4)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionARC})
+
+	values := []string{"a", "b", "c", "d"}
+	for _, val := range values {
+		cache.Set(val, &val)
+	}
+
+	// a and b move to T2
+	cache.Get("a")
+	cache.Get("b")
+
+	// A scan of new keys should only churn T1
+	for i := 0; i < 10; i++ {
+		value := fmt.Sprintf("scan%d", i)
+		cache.Set(value, &value)
+	}
+
+	if _, found := cache.Get("a"); !found {
+		t.Errorf("a should survive the scan (frequently used)")
+	}
+	if _, found := cache.Get("b"); !found {
+		t.Errorf("b should survive the scan (frequently used)")
+	}
+	if cache.Len() != 4 {
+		t.Errorf("Cache should hold 4 items, got %d", cache.Len())
+	}
+}
+
+// TestARCGhostHitAdaptsTarget tests that a hit in B1 grows the T1 target and
+// re-admits the key into T2
+func TestARCGhostHitAdaptsTarget(t *testing.T) {
+	maxItems := int64(2)
+	policy := NewARCPolicy[string]().(*arcPolicy[string])
+	cache := New[string, int](&Config{MaxItems: &maxItems}, WithEvictionPolicy[string, int](policy))
+
+	one, two, three := 1, 2, 3
+	cache.Set("a", &one)
+	cache.Set("b", &two)
+	cache.Get("b")         // b moves to T2
+	cache.Set("c", &three) // evicts a from T1 into B1
+
+	if entry := policy.entries["a"]; entry == nil || entry.list != arcB1 {
+		t.Fatalf("a should be a ghost in B1 after eviction")
+	}
+
+	cache.Set("a", &one) // ghost hit
+	if policy.target != 1 {
+		t.Errorf("Expected T1 target 1 after a B1 hit, got %d", policy.target)
+	}
+	if entry := policy.entries["a"]; entry == nil || entry.list != arcT2 {
+		t.Errorf("a should be re-admitted into T2")
+	}
+
+	// Deleting a resident key must not leave a ghost behind
+	cache.Delete("a")
+	if _, exists := policy.entries["a"]; exists {
+		t.Errorf("Deleted key should not be tracked as a ghost")
+	}
+}
+
+// TestARCSizeLimit tests that ARC honours a byte based limit
+func TestARCSizeLimit(t *testing.T) {
+	maxSize := int64(400)
+	cache := New[string, string](&Config{Size: &maxSize, Eviction: EvictionARC})
+
+	for i := 0; i < 50; i++ {
+		value := fmt.Sprintf("value-%d", i)
+		cache.Set(fmt.Sprintf("key-%d", i), &value)
+		if i%3 == 0 {
+			cache.Get(fmt.Sprintf("key-%d", i))
+		}
+	}
+
+	if cache.Len() == 0 || cache.Len() >= 50 {
+		t.Errorf("Expected the size limit to evict some but not all items, got %d", cache.Len())
+	}
+	if _, found := cache.Get("key-49"); !found {
+		t.Errorf("The last added item should be present")
+	}
+}
+
+// TestSLRUProtectsRereadKeys tests that keys read a second time survive new inserts
+func TestSLRUProtectsRereadKeys(t *testing.T) {
+	maxItems := int64(4)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionSLRU, ProtectedRatio: 0.5})
+
+	values := []string{"a", "b", "c", "d"}
+	for _, val := range values {
+		cache.Set(val, &val)
+	}
+
+	// a is promoted to the protected segment
+	cache.Get("a")
+
+	for i := 0; i < 10; i++ {
+		value := fmt.Sprintf("new%d", i)
+		cache.Set(value, &value)
+	}
+
+	if _, found := cache.Get("a"); !found {
+		t.Errorf("a should stay in the protected segment")
+	}
+	if _, found := cache.Get("b"); found {
+		t.Errorf("b should have been evicted from probation")
+	}
+}
+
+// TestSLRUDemotion tests that keys pushed out of protected go back to probation
+func TestSLRUDemotion(t *testing.T) {
+	policy := NewSLRUPolicy[string](0.5).(*slruPolicy[string])
+	for _, key := range []string{"a", "b", "c", "d"} {
+		policy.RecordInsert(key)
+	}
+
+	policy.RecordAccess("a")
+	policy.RecordAccess("b")
+	policy.RecordAccess("c") // protected limit is 2, so a is demoted
+
+	if policy.entries["a"].protected {
+		t.Errorf("a should have been demoted to probation")
+	}
+	if !policy.entries["b"].protected || !policy.entries["c"].protected {
+		t.Errorf("b and c should be protected")
+	}
+
+	// d is the oldest probation key, a was demoted to the newest end
+	if victim, _ := policy.Victim(nil); victim != "d" {
+		t.Errorf("Expected victim d, got %s", victim)
+	}
+}
+
+// TestClockSecondChance tests that a referenced key is skipped once by the clock hand
+func TestClockSecondChance(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionClock})
+
+	values := []string{"first", "second", "third"}
+	for i, val := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &val)
+	}
+
+	// item0 gets its reference bit set
+	cache.Get("item0")
+
+	fourth := "fourth"
+	cache.Set("item3", &fourth)
+
+	if _, found := cache.Get("item1"); found {
+		t.Errorf("item1 should have been evicted (unreferenced)")
+	}
+	for _, key := range []string{"item0", "item2", "item3"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("%s should remain", key)
+		}
+	}
+}
+
+// TestClockConcurrentReads tests that reads under the shared lock race safely with writes
+func TestClockConcurrentReads(t *testing.T) {
+	maxItems := int64(50)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: EvictionClock})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				key := (id*31 + j) % 100
+				if j%4 == 0 {
+					value := j
+					cache.Set(key, &value)
+				} else {
+					cache.Get(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if cache.Len() > 50 {
+		t.Errorf("Cache should respect MaxItems, got %d items", cache.Len())
+	}
+}
+
+// TestS3FIFOOneHitWonders tests that keys hit while in the small queue survive a scan
+func TestS3FIFOOneHitWonders(t *testing.T) {
+	maxItems := int64(20)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: EvictionS3FIFO})
+
+	for i := 0; i < 20; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+	// Keys 0-4 are hit and will be promoted to main when they leave small
+	for i := 0; i < 5; i++ {
+		cache.Get(i)
+	}
+
+	for i := 100; i < 200; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, found := cache.Get(i); !found {
+			t.Errorf("key %d should survive the scan in the main queue", i)
+		}
+	}
+	if cache.Len() != 20 {
+		t.Errorf("Cache should hold 20 items, got %d", cache.Len())
+	}
+}
+
+// TestS3FIFOGhostReadmission tests that a recently evicted key returns straight to main
+func TestS3FIFOGhostReadmission(t *testing.T) {
+	maxItems := int64(2)
+	policy := NewS3FIFOPolicy[string]().(*s3FIFOPolicy[string])
+	cache := New[string, int](&Config{MaxItems: &maxItems}, WithEvictionPolicy[string, int](policy))
+
+	one, two, three := 1, 2, 3
+	cache.Set("a", &one)
+	cache.Set("b", &two)
+	cache.Set("c", &three) // a leaves small unhit and becomes a ghost
+
+	if entry := policy.entries["a"]; entry == nil || entry.queue != s3Ghost {
+		t.Fatalf("a should be a ghost after eviction")
+	}
+
+	cache.Set("a", &one)
+	if entry := policy.entries["a"]; entry == nil || entry.queue != s3Main {
+		t.Errorf("a should be re-admitted into the main queue")
+	}
+}
+
+// TestSampledLRUEvictsIdleKey tests that sampling every key reproduces exact LRU
+func TestSampledLRUEvictsIdleKey(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionSampledLRU, EvictionSamples: 10})
+
+	values := []string{"first", "second", "third"}
+	for i, val := range values {
+		cache.Set(fmt.Sprintf("item%d", i), &val)
+		time.Sleep(time.Millisecond)
+	}
+	cache.Get("item0")
+
+	fourth := "fourth"
+	cache.Set("item3", &fourth)
+
+	if _, found := cache.Get("item1"); found {
+		t.Errorf("item1 should have been evicted (longest idle)")
+	}
+	if _, found := cache.Get("item0"); !found {
+		t.Errorf("item0 should remain (was recently accessed)")
+	}
+}
+
+// TestVolatileEvictionOnlyTouchesTTLKeys tests the volatile-lru and volatile-ttl variants
+func TestVolatileEvictionOnlyTouchesTTLKeys(t *testing.T) {
+	for _, mode := range []EvictionMode{EvictionVolatileLRU, EvictionVolatileTTL} {
+		maxItems := int64(3)
+		cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: mode, EvictionSamples: 10})
+
+		value := "value"
+		cache.Set("persistent", &value)
+		cache.SetWithTTL("long", &value, time.Hour)
+		cache.SetWithTTL("short", &value, time.Minute)
+		cache.Set("new", &value)
+
+		if _, found := cache.Get("persistent"); !found {
+			t.Errorf("mode %d: keys without TTL must not be evicted", mode)
+		}
+		if _, found := cache.Get("new"); !found {
+			t.Errorf("mode %d: new key should be present", mode)
+		}
+		if mode == EvictionVolatileTTL {
+			if _, found := cache.Get("short"); found {
+				t.Errorf("volatile-ttl should evict the key closest to expiry")
+			}
+		}
+		if cache.Len() != 3 {
+			t.Errorf("mode %d: cache should hold 3 items, got %d", mode, cache.Len())
+		}
+	}
+}
+
+// TestVolatileEvictionReportsCacheFull tests that the volatile modes return
+// ErrCacheFull instead of growing past the limit when no key has a TTL
+func TestVolatileEvictionReportsCacheFull(t *testing.T) {
+	for _, mode := range []EvictionMode{EvictionVolatileLRU, EvictionVolatileTTL} {
+		maxItems := int64(3)
+		cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: mode})
+
+		for i := 0; i < 10; i++ {
+			value := i
+			err := cache.Set(i, &value)
+			if i < 3 && err != nil {
+				t.Errorf("mode %d: Set %d should fit: %v", mode, i, err)
+			}
+			if i >= 3 && err != ErrCacheFull {
+				t.Errorf("mode %d: Set %d expected ErrCacheFull, got %v", mode, i, err)
+			}
+		}
+		if cache.Len() != 3 {
+			t.Errorf("mode %d: cache should hold 3 items, got %d", mode, cache.Len())
+		}
+
+		// Once a key has a TTL it can make room again
+		value := 0
+		cache.SetWithTTL(0, &value, time.Hour)
+		if err := cache.Set(10, &value); err != nil {
+			t.Errorf("mode %d: Set should evict the TTL key: %v", mode, err)
+		}
+	}
+}
+
+// TestSampledEvictionSkipsPinnedKeys tests that the sampled modes still find
+// the few unpinned keys when almost every key is pinned
+func TestSampledEvictionSkipsPinnedKeys(t *testing.T) {
+	for _, mode := range []EvictionMode{EvictionSampledLRU, EvictionVolatileLRU, EvictionVolatileTTL, EvictionRandom} {
+		maxItems := int64(100)
+		cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: mode})
+
+		for i := 0; i < 100; i++ {
+			value := i
+			cache.SetWithTTL(i, &value, time.Hour)
+			if i < 95 {
+				cache.Pin(i)
+			}
+		}
+
+		for i := 100; i < 150; i++ {
+			value := i
+			if err := cache.SetWithTTL(i, &value, time.Hour); err != nil {
+				t.Fatalf("mode %d: Set %d should evict an unpinned key: %v", mode, i, err)
+			}
+		}
+		for i := 0; i < 95; i++ {
+			if _, found := cache.Get(i); !found {
+				t.Fatalf("mode %d: pinned key %d should not be evicted", mode, i)
+			}
+		}
+		if cache.Len() != 100 {
+			t.Errorf("mode %d: cache should hold 100 items, got %d", mode, cache.Len())
+		}
+	}
+}
+
+// TestRandomEviction tests that allkeys-random keeps the cache within its limit
+func TestRandomEviction(t *testing.T) {
+	maxItems := int64(10)
+	cache := New[int, int](&Config{MaxItems: &maxItems, Eviction: EvictionRandom})
+
+	for i := 0; i < 100; i++ {
+		value := i
+		cache.Set(i, &value)
+	}
+
+	if cache.Len() != 10 {
+		t.Errorf("Cache should hold 10 items, got %d", cache.Len())
+	}
+	if _, found := cache.Get(99); !found {
+		t.Errorf("The last added item should be present")
+	}
+}
+
+// TestGDSFKeepsExpensiveSmallItems tests that GDSF evicts cheap and large items first
+func TestGDSFKeepsExpensiveSmallItems(t *testing.T) {
+	maxItems := int64(3)
+	cache := New[string, string](&Config{MaxItems: &maxItems, Eviction: EvictionGDSF})
+
+	small := "s"
+	large := "a much larger value that takes a lot of room in the cache"
+	cache.SetWithCost("expensive", &small, 100)
+	cache.SetWithCost("cheap-large", &large, 1)
+	cache.SetWithCost("cheap-small", &small, 1)
+
+	cache.SetWithCost("new", &small, 10)
+
+	if _, found := cache.Get("cheap-large"); found {
+		t.Errorf("cheap-large should have been evicted first")
+	}
+	if _, found := cache.Get("expensive"); !found {
+		t.Errorf("expensive should remain")
+	}
+
+	// Hits raise priority: cheap-small outlives new after being read often
+	for i := 0; i < 20; i++ {
+		cache.Get("cheap-small")
+	}
+	another := "x"
+	cache.Set("another", &another)
+	if _, found := cache.Get("cheap-small"); !found {
+		t.Errorf("cheap-small should survive after frequent hits")
+	}
+	if _, found := cache.Get("new"); found {
+		t.Errorf("new should have been evicted")
+	}
+}
+
+// TestGDSFInflation tests that the inflation value rises to the evicted priority
+func TestGDSFInflation(t *testing.T) {
+	maxItems := int64(1)
+	policy := newGDSFPolicy(New[string, string](nil).(*cache[string, string])).(*gdsfPolicy[string, string])
+	cache := policy.c
+	cache.maxItems = &maxItems
+	cache.policy = policy
+
+	value := "v"
+	cache.SetWithCost("a", &value, 50)
+	cache.SetWithCost("b", &value, 50)
+
+	if policy.inflation <= 0 {
+		t.Errorf("Inflation should rise after an eviction, got %f", policy.inflation)
+	}
+	if entry := policy.entries["b"]; entry == nil || entry.priority <= policy.inflation {
+		t.Errorf("New entries should be prioritised above the inflation value")
+	}
+}
@@ -0,0 +1,277 @@
+package goinmemcache
+
+import "hash/maphash"
+
+const (
+	tinyLFUDefaultCapacity = 1024
+	tinyLFUWindowPercent   = 1  // share of resident keys kept in the admission window
+	tinyLFUProtectedRatio  = 80 // share of the main region kept in the protected segment
+	sketchDepth            = 4
+	sketchMaxCount         = 15
+)
+
+// tinyLFU segments
+const (
+	segmentWindow uint8 = iota
+	segmentProbation
+	segmentProtected
+)
+
+// tinyLFUEntry locates a key inside one of the policy's segments
+type tinyLFUEntry[K comparable] struct {
+	node    *listNode[K]
+	segment uint8
+}
+
+// tinyLFUPolicy implements W-TinyLFU: new keys enter a small LRU window and
+// only move into the segmented main region when their estimated frequency
+// beats the main region's eviction victim
+type tinyLFUPolicy[K comparable] struct {
+	window    *keyList[K]
+	probation *keyList[K]
+	protected *keyList[K]
+	entries   map[K]*tinyLFUEntry[K]
+	sketch    *countMinSketch[K]
+}
+
+// NewTinyLFUPolicy returns a W-TinyLFU eviction policy whose frequency
+// sketch is sized for roughly capacity keys
+func NewTinyLFUPolicy[K comparable](capacity int) EvictionPolicy[K] {
+	if capacity <= 0 {
+		capacity = tinyLFUDefaultCapacity
+	}
+	return &tinyLFUPolicy[K]{
+		window:    newKeyList[K](),
+		probation: newKeyList[K](),
+		protected: newKeyList[K](),
+		entries:   make(map[K]*tinyLFUEntry[K]),
+		sketch:    newCountMinSketch[K](capacity),
+	}
+}
+
+func (p *tinyLFUPolicy[K]) RecordInsert(key K) {
+	p.sketch.increment(key)
+	if entry, exists := p.entries[key]; exists {
+		p.touch(entry)
+		return
+	}
+
+	node := &listNode[K]{key: key}
+	p.window.pushBack(node)
+	p.entries[key] = &tinyLFUEntry[K]{node: node, segment: segmentWindow}
+
+	// While the cache is filling up nothing is evicted, so keys leaving the
+	// window move into the main region without competing
+	for p.window.len > p.windowTarget() {
+		p.moveToProbation(p.window.front())
+	}
+}
+
+func (p *tinyLFUPolicy[K]) RecordAccess(key K) {
+	p.sketch.increment(key)
+	if entry, exists := p.entries[key]; exists {
+		p.touch(entry)
+	}
+}
+
+func (p *tinyLFUPolicy[K]) RecordRemoval(key K) {
+	if entry, exists := p.entries[key]; exists {
+		p.segmentList(entry.segment).remove(entry.node)
+		delete(p.entries, key)
+	}
+}
+
+func (p *tinyLFUPolicy[K]) Victim(skip func(K) bool) (K, bool) {
+	// Once the window is at its share, its oldest key competes with the
+	// main region's victim for admission and the loser is evicted
+	if p.window.len >= p.windowTarget() {
+		if candidate := p.window.firstMatch(skip); candidate != nil {
+			victim := p.mainVictim(skip)
+			if victim == nil || p.sketch.estimate(candidate.key) <= p.sketch.estimate(victim.key) {
+				return candidate.key, true
+			}
+			p.moveToProbation(candidate)
+			return victim.key, true
+		}
+	}
+
+	if victim := p.mainVictim(skip); victim != nil {
+		return victim.key, true
+	}
+	if candidate := p.window.firstMatch(skip); candidate != nil {
+		return candidate.key, true
+	}
+	var zeroK K
+	return zeroK, false
+}
+
+func (p *tinyLFUPolicy[K]) Reset() {
+	p.window.reset()
+	p.probation.reset()
+	p.protected.reset()
+	p.entries = make(map[K]*tinyLFUEntry[K])
+	p.sketch.clear()
+}
+
+// touch records a hit on a resident key, promoting it from probation to
+// protected and demoting the oldest protected key if that segment is full
+func (p *tinyLFUPolicy[K]) touch(entry *tinyLFUEntry[K]) {
+	switch entry.segment {
+	case segmentWindow:
+		p.window.moveToBack(entry.node)
+	case segmentProtected:
+		p.protected.moveToBack(entry.node)
+	case segmentProbation:
+		p.probation.remove(entry.node)
+		p.protected.pushBack(entry.node)
+		entry.segment = segmentProtected
+
+		mainLen := p.probation.len + p.protected.len
+		for p.protected.len*100 > mainLen*tinyLFUProtectedRatio {
+			demoted := p.protected.popFront()
+			p.probation.pushBack(demoted)
+			p.entries[demoted.key].segment = segmentProbation
+		}
+	}
+}
+
+// moveToProbation moves a window key to the newest end of probation
+func (p *tinyLFUPolicy[K]) moveToProbation(node *listNode[K]) {
+	p.window.remove(node)
+	p.probation.pushBack(node)
+	p.entries[node.key].segment = segmentProbation
+}
+
+// mainVictim returns the oldest main-region key, preferring probation
+func (p *tinyLFUPolicy[K]) mainVictim(skip func(K) bool) *listNode[K] {
+	if node := p.probation.firstMatch(skip); node != nil {
+		return node
+	}
+	return p.protected.firstMatch(skip)
+}
+
+// windowTarget returns how many keys the admission window may hold
+func (p *tinyLFUPolicy[K]) windowTarget() int {
+	total := p.window.len + p.probation.len + p.protected.len
+	return max(1, total*tinyLFUWindowPercent/100)
+}
+
+func (p *tinyLFUPolicy[K]) segmentList(segment uint8) *keyList[K] {
+	switch segment {
+	case segmentProbation:
+		return p.probation
+	case segmentProtected:
+		return p.protected
+	default:
+		return p.window
+	}
+}
+
+// countMinSketch estimates key access frequencies with saturating counters.
+// A doorkeeper bitset absorbs the first access of every key so one-hit
+// wonders never reach the counters, and all counters are halved once
+// sampleSize increments have been recorded so old popularity fades.
+type countMinSketch[K comparable] struct {
+	seed       maphash.Seed
+	counters   [sketchDepth][]uint8
+	doorkeeper []uint64
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+func newCountMinSketch[K comparable](capacity int) *countMinSketch[K] {
+	width := 1
+	for width < capacity {
+		width <<= 1
+	}
+
+	s := &countMinSketch[K]{
+		seed:       maphash.MakeSeed(),
+		doorkeeper: make([]uint64, (width+63)/64),
+		mask:       uint64(width - 1),
+		sampleSize: 10 * width,
+	}
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// indexes returns the counter index of key in every row
+func (s *countMinSketch[K]) indexes(key K) [sketchDepth]uint64 {
+	hash := maphash.Comparable(s.seed, key)
+	h1, h2 := hash&0xffffffff, hash>>32|1
+	var idx [sketchDepth]uint64
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return idx
+}
+
+func (s *countMinSketch[K]) increment(key K) {
+	idx := s.indexes(key)
+
+	// The first sighting only sets the doorkeeper bits
+	if !s.doorkeeperContains(idx) {
+		s.doorkeeperAdd(idx)
+	} else {
+		for i, j := range idx {
+			if s.counters[i][j] < sketchMaxCount {
+				s.counters[i][j]++
+			}
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.age()
+	}
+}
+
+func (s *countMinSketch[K]) estimate(key K) int {
+	idx := s.indexes(key)
+	minCount := uint8(sketchMaxCount)
+	for i, j := range idx {
+		minCount = min(minCount, s.counters[i][j])
+	}
+	if s.doorkeeperContains(idx) {
+		return int(minCount) + 1
+	}
+	return int(minCount)
+}
+
+// age halves every counter and clears the doorkeeper
+func (s *countMinSketch[K]) age() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] >>= 1
+		}
+	}
+	clear(s.doorkeeper)
+	s.additions /= 2
+}
+
+func (s *countMinSketch[K]) clear() {
+	for i := range s.counters {
+		clear(s.counters[i])
+	}
+	clear(s.doorkeeper)
+	s.additions = 0
+}
+
+// The doorkeeper uses the first two row indexes as its bloom filter hashes
+func (s *countMinSketch[K]) doorkeeperContains(idx [sketchDepth]uint64) bool {
+	for _, j := range idx[:2] {
+		if s.doorkeeper[j/64]&(1<<(j%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *countMinSketch[K]) doorkeeperAdd(idx [sketchDepth]uint64) {
+	for _, j := range idx[:2] {
+		s.doorkeeper[j/64] |= 1 << (j % 64)
+	}
+}
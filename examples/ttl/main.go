@@ -56,7 +56,8 @@ func main() {
 	time.Sleep(6 * time.Second)
 
 	// Manually trigger cleanup to see expired items removed
-	myCache.CleanupExpired()
+	removed := myCache.CleanupExpired()
+	fmt.Printf("Cleanup removed %d expired items\n", removed)
 
 	if _, found := myCache.Get("user:123"); !found {
 		fmt.Println("user:123 has expired ✓")
@@ -0,0 +1,168 @@
+package goinmemcache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// ExpirationMode selects the data structure that tracks when items expire
+type ExpirationMode int
+
+const (
+	// ExpirationHeap keeps expirations in a min-heap ordered by deadline.
+	// Scheduling and cancelling cost O(log n) and the earliest deadline is
+	// known exactly. This is the default.
+	ExpirationHeap ExpirationMode = iota
+	// ExpirationTimingWheel keeps expirations in a hierarchical timing
+	// wheel with O(1) scheduling and cancelling. Items are reclaimed with
+	// a resolution of wheelTick, which suits caches with millions of TTL
+	// entries.
+	ExpirationTimingWheel
+	// ExpirationSampling keeps only each key's deadline, without ordering.
+	// Expired items are removed when read or by a periodic cycle that
+	// samples random keys, as Redis does, so memory and write costs are
+	// lowest but expired items may linger. PreciseExpiration has no
+	// effect in this mode, and the cleanup interval defaults to 100ms.
+	ExpirationSampling
+)
+
+// expirationQueue tracks the expiration time of keys with a TTL. It is
+// not safe for concurrent use; the cache calls it under its write lock.
+type expirationQueue[K comparable] interface {
+	// schedule sets the expiration time of a key, adding it if needed
+	schedule(key K, expireTime time.Time)
+	// remove stops tracking a key
+	remove(key K)
+	// expire stops tracking up to limit keys due at now and passes them to
+	// fn. It reports whether more keys are due.
+	expire(now time.Time, limit int, fn func(key K)) bool
+	// next returns a time no later than the earliest expiration, and
+	// reports false if no keys are tracked
+	next() (time.Time, bool)
+	// each calls fn for tracked keys in unspecified order until it returns false
+	each(fn func(key K) bool)
+	len() int
+	reset()
+}
+
+// newExpirationQueue creates the expiration queue selected by mode
+func newExpirationQueue[K comparable](mode ExpirationMode, now time.Time) expirationQueue[K] {
+	switch mode {
+	case ExpirationTimingWheel:
+		return newTimingWheel[K](wheelTick, now)
+	case ExpirationSampling:
+		return newSamplingQueue[K]()
+	default:
+		return newHeapQueue[K]()
+	}
+}
+
+// expirationEntry represents an item in the expiration queue
+type expirationEntry[K comparable] struct {
+	key        K
+	expireTime time.Time
+	index      int // index in the heap
+}
+
+// expirationHeap implements heap.Interface for expiration entries
+type expirationHeap[K comparable] []*expirationEntry[K]
+
+func (h expirationHeap[K]) Len() int           { return len(h) }
+func (h expirationHeap[K]) Less(i, j int) bool { return h[i].expireTime.Before(h[j].expireTime) }
+func (h expirationHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expirationHeap[K]) Push(x interface{}) {
+	entry := x.(*expirationEntry[K])
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *expirationHeap[K]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.index = -1
+	*h = old[0 : n-1]
+	return entry
+}
+
+// heapQueue is the min-heap expiration queue with a map for fast lookup
+type heapQueue[K comparable] struct {
+	queue   expirationHeap[K]
+	entries map[K]*expirationEntry[K]
+}
+
+func newHeapQueue[K comparable]() *heapQueue[K] {
+	return &heapQueue[K]{
+		queue:   make(expirationHeap[K], 0),
+		entries: make(map[K]*expirationEntry[K]),
+	}
+}
+
+func (q *heapQueue[K]) schedule(key K, expireTime time.Time) {
+	if entry, exists := q.entries[key]; exists {
+		entry.expireTime = expireTime
+		heap.Fix(&q.queue, entry.index)
+		return
+	}
+	entry := &expirationEntry[K]{
+		key:        key,
+		expireTime: expireTime,
+	}
+	heap.Push(&q.queue, entry)
+	q.entries[key] = entry
+}
+
+func (q *heapQueue[K]) remove(key K) {
+	if entry, exists := q.entries[key]; exists {
+		if entry.index >= 0 && entry.index < len(q.queue) {
+			heap.Remove(&q.queue, entry.index)
+		}
+		delete(q.entries, key)
+	}
+}
+
+func (q *heapQueue[K]) expire(now time.Time, limit int, fn func(key K)) bool {
+	// Remove expired entries from the front of the heap
+	for expired := 0; len(q.queue) > 0; expired++ {
+		entry := q.queue[0]
+		if entry.expireTime.After(now) {
+			break // No more expired entries
+		}
+		if expired == limit {
+			return true
+		}
+		heap.Pop(&q.queue)
+		delete(q.entries, entry.key)
+		fn(entry.key)
+	}
+	return false
+}
+
+func (q *heapQueue[K]) next() (time.Time, bool) {
+	if len(q.queue) == 0 {
+		return time.Time{}, false
+	}
+	return q.queue[0].expireTime, true
+}
+
+func (q *heapQueue[K]) each(fn func(key K) bool) {
+	for key := range q.entries {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (q *heapQueue[K]) len() int {
+	return len(q.queue)
+}
+
+func (q *heapQueue[K]) reset() {
+	q.queue = make(expirationHeap[K], 0)
+	q.entries = make(map[K]*expirationEntry[K])
+}
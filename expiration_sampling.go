@@ -0,0 +1,83 @@
+package goinmemcache
+
+import "time"
+
+const (
+	activeExpireSamples   = 20                     // keys sampled per round, as in Redis
+	activeExpireThreshold = 0.25                   // repeat while more than this share was expired
+	activeExpireInterval  = 100 * time.Millisecond // default cleanup interval for ExpirationSampling
+)
+
+// samplingQueue tracks only the deadline of each key with a TTL. Instead
+// of ordering keys by deadline it expires them the way Redis does: items
+// are checked lazily on access, and each cleanup samples random keys and
+// keeps sampling while more than activeExpireThreshold of a sample was
+// expired. Expired keys can therefore linger until they are read or
+// sampled, in exchange for no per-key allocations and O(1) writes.
+type samplingQueue[K comparable] struct {
+	deadlines map[K]int64 // unix nanoseconds
+}
+
+func newSamplingQueue[K comparable]() *samplingQueue[K] {
+	return &samplingQueue[K]{deadlines: make(map[K]int64)}
+}
+
+func (q *samplingQueue[K]) schedule(key K, expireTime time.Time) {
+	q.deadlines[key] = expireTime.UnixNano()
+}
+
+func (q *samplingQueue[K]) remove(key K) {
+	delete(q.deadlines, key)
+}
+
+func (q *samplingQueue[K]) expire(now time.Time, limit int, fn func(key K)) bool {
+	deadline := now.UnixNano()
+	expired := 0
+	for len(q.deadlines) > 0 {
+		// Map iteration starts at a random position, which serves as the
+		// random sample
+		sampled, due := 0, 0
+		for key, at := range q.deadlines {
+			if sampled == activeExpireSamples {
+				break
+			}
+			sampled++
+			if at > deadline {
+				continue
+			}
+			if expired == limit {
+				return true
+			}
+			delete(q.deadlines, key)
+			fn(key)
+			expired++
+			due++
+		}
+
+		if float64(due) <= activeExpireThreshold*float64(sampled) {
+			break // Few enough expired keys are left
+		}
+	}
+	return false
+}
+
+// next reports false because sampling does not know the earliest deadline
+func (q *samplingQueue[K]) next() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (q *samplingQueue[K]) each(fn func(key K) bool) {
+	for key := range q.deadlines {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (q *samplingQueue[K]) len() int {
+	return len(q.deadlines)
+}
+
+func (q *samplingQueue[K]) reset() {
+	q.deadlines = make(map[K]int64)
+}
@@ -0,0 +1,296 @@
+package goinmemcache
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// TestTimingWheelExpiresAtDeadline tests that keys fire no earlier than their
+// deadline and no later than the tick after it, across cascades
+func TestTimingWheelExpiresAtDeadline(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	wheel := newTimingWheel[int](wheelTick, start)
+
+	// Deadlines spread across level 0, level 1 and level 2
+	offsets := []time.Duration{
+		5 * time.Millisecond,
+		300 * time.Millisecond,
+		2 * time.Second,
+		90 * time.Second,
+		20 * time.Minute,
+	}
+	for i, offset := range offsets {
+		wheel.schedule(i, start.Add(offset))
+	}
+
+	fired := make(map[int]time.Time)
+	for now := start; now.Before(start.Add(21 * time.Minute)); now = now.Add(wheelTick) {
+		wheel.expire(now, 100, func(key int) {
+			fired[key] = now
+		})
+	}
+
+	for i, offset := range offsets {
+		deadline := start.Add(offset)
+		at, ok := fired[i]
+		if !ok {
+			t.Errorf("Key %d with deadline +%v never fired", i, offset)
+			continue
+		}
+		if at.Before(deadline) || at.Sub(deadline) > wheelTick {
+			t.Errorf("Key %d with deadline +%v fired at +%v", i, offset, at.Sub(start))
+		}
+	}
+	if wheel.len() != 0 {
+		t.Errorf("Expected empty wheel, got %d entries", wheel.len())
+	}
+}
+
+// TestTimingWheelRescheduleAndRemove tests moving and cancelling keys
+func TestTimingWheelRescheduleAndRemove(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	wheel := newTimingWheel[string](wheelTick, start)
+
+	wheel.schedule("moved", start.Add(50*time.Millisecond))
+	wheel.schedule("removed", start.Add(50*time.Millisecond))
+	wheel.schedule("far", start.Add(100*365*24*time.Hour)) // beyond the wheel's span
+	wheel.schedule("moved", start.Add(time.Hour))
+	wheel.remove("removed")
+
+	var fired []string
+	wheel.expire(start.Add(time.Second), 100, func(key string) {
+		fired = append(fired, key)
+	})
+	if len(fired) != 0 {
+		t.Errorf("Expected nothing to fire, got %v", fired)
+	}
+
+	if next, ok := wheel.next(); !ok || next.After(start.Add(time.Hour)) {
+		t.Errorf("next should not be after the earliest deadline, got %v", next.Sub(start))
+	}
+
+	wheel.expire(start.Add(time.Hour+wheelTick), 100, func(key string) {
+		fired = append(fired, key)
+	})
+	if len(fired) != 1 || fired[0] != "moved" {
+		t.Errorf("Expected only the rescheduled key to fire, got %v", fired)
+	}
+	if wheel.len() != 1 {
+		t.Errorf("Expected the far key to remain, got %d entries", wheel.len())
+	}
+}
+
+// TestTimingWheelSkipsEmptyTicks tests that large time steps fire keys at
+// the first expire call past their deadline and do not turn the wheel one
+// tick at a time
+func TestTimingWheelSkipsEmptyTicks(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	wheel := newTimingWheel[int](wheelTick, start)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	deadlines := make(map[int]time.Time)
+	for i := 0; i < 500; i++ {
+		deadlines[i] = start.Add(time.Duration(rng.Int64N(int64(40 * 24 * time.Hour))))
+		wheel.schedule(i, deadlines[i])
+	}
+
+	previous := start
+	for now := start; wheel.len() > 0; now = now.Add(time.Duration(rng.Int64N(int64(12 * time.Hour)))) {
+		wheel.expire(now, 1000, func(key int) {
+			deadline := deadlines[key]
+			if now.Before(deadline) || !previous.Before(deadline.Add(wheelTick)) {
+				t.Errorf("Key %d with deadline %v fired at %v (previous call %v)", key, deadline, now, previous)
+			}
+			delete(deadlines, key)
+		})
+		previous = now
+	}
+
+	// One far key: a month of fake time must not be walked tick by tick
+	wheel.schedule(-1, start.Add(365*24*time.Hour))
+	began := time.Now()
+	wheel.expire(previous.Add(31*24*time.Hour), 1000, func(int) {
+		t.Errorf("The far key should not fire")
+	})
+	if elapsed := time.Since(began); elapsed > 100*time.Millisecond {
+		t.Errorf("Expiring across an empty month took %v", elapsed)
+	}
+
+	// The precise timer is armed for the next cascade, not every rotation
+	if next, _ := wheel.next(); next.Sub(previous) < time.Hour {
+		t.Errorf("next should skip empty rotations, got +%v", next.Sub(previous))
+	}
+}
+
+// TestTimingWheelExpiration tests a cache using the timing wheel backend
+func TestTimingWheelExpiration(t *testing.T) {
+	cache := New[string, string](&Config{Expiration: ExpirationTimingWheel})
+	defer cache.Close()
+
+	value := "value"
+	cache.SetWithTTL("short", &value, 20*time.Millisecond)
+	cache.SetWithTTL("long", &value, time.Hour)
+	cache.SetWithTTL("persisted", &value, 20*time.Millisecond)
+	cache.Persist("persisted")
+
+	time.Sleep(50 * time.Millisecond)
+	cache.CleanupExpired()
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected the short item to be removed, got %d items", cache.Len())
+	}
+	if _, found := cache.Get("persisted"); !found {
+		t.Errorf("Persisted item should not expire")
+	}
+}
+
+// TestTimingWheelPreciseExpiration tests the precise timer with the timing wheel
+func TestTimingWheelPreciseExpiration(t *testing.T) {
+	cache := New[string, string](&Config{Expiration: ExpirationTimingWheel, PreciseExpiration: true})
+	defer cache.Close()
+
+	value := "value"
+	cache.SetWithTTL("first", &value, 20*time.Millisecond)
+	cache.SetWithTTL("second", &value, 700*time.Millisecond) // cascades from level 1
+
+	time.Sleep(50 * time.Millisecond)
+	if cache.Len() != 1 {
+		t.Errorf("Expected first item to be removed by the timer, got %d items", cache.Len())
+	}
+
+	time.Sleep(700 * time.Millisecond)
+	if cache.Len() != 0 {
+		t.Errorf("Expected second item to be removed by the timer, got %d items", cache.Len())
+	}
+}
+
+// TestExpireBatchLimit tests that both backends stop after limit keys and
+// resume where they stopped
+func TestExpireBatchLimit(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	queues := map[string]expirationQueue[int]{
+		"heap":  newHeapQueue[int](),
+		"wheel": newTimingWheel[int](wheelTick, start),
+	}
+
+	for name, queue := range queues {
+		for i := 0; i < 25; i++ {
+			// Several keys share each tick
+			queue.schedule(i, start.Add(time.Duration(1+i/5)*wheelTick))
+		}
+		queue.schedule(100, start.Add(time.Hour))
+
+		now := start.Add(time.Second)
+		seen := make(map[int]bool)
+		batches := 0
+		for more := true; more; batches++ {
+			count := 0
+			more = queue.expire(now, 10, func(key int) {
+				seen[key] = true
+				count++
+			})
+			if count > 10 {
+				t.Errorf("%s: batch expired %d keys, limit is 10", name, count)
+			}
+		}
+
+		if len(seen) != 25 || seen[100] {
+			t.Errorf("%s: expected the 25 due keys to expire, got %d", name, len(seen))
+		}
+		if batches != 3 {
+			t.Errorf("%s: expected 3 batches, got %d", name, batches)
+		}
+		if queue.len() != 1 {
+			t.Errorf("%s: expected the future key to remain, got %d", name, queue.len())
+		}
+	}
+}
+
+// TestCleanupExpiredInBatches tests that a cleanup larger than the batch size
+// removes every expired item and reports the count
+func TestCleanupExpiredInBatches(t *testing.T) {
+	cache := New[int, int](&Config{CleanupBatchSize: 16})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.SetWithTTL(i, &i, 10*time.Millisecond)
+	}
+	for i := 100; i < 110; i++ {
+		cache.SetWithTTL(i, &i, time.Hour)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if removed := cache.CleanupExpired(); removed != 100 {
+		t.Errorf("Expected 100 items removed, got %d", removed)
+	}
+	if cache.Len() != 10 {
+		t.Errorf("Expected 10 items left, got %d", cache.Len())
+	}
+	if removed := cache.CleanupExpired(); removed != 0 {
+		t.Errorf("Expected nothing left to remove, got %d", removed)
+	}
+}
+
+// TestSamplingQueueActiveCycle tests that sampling keeps going while many
+// sampled keys are expired and never removes live keys
+func TestSamplingQueueActiveCycle(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	queue := newSamplingQueue[int]()
+	for i := 0; i < 2000; i++ {
+		if i%2 == 0 {
+			queue.schedule(i, start.Add(time.Second)) // expired
+		} else {
+			queue.schedule(i, start.Add(time.Hour))
+		}
+	}
+
+	removed := 0
+	queue.expire(start.Add(time.Minute), 10000, func(key int) {
+		if key%2 != 0 {
+			t.Fatalf("Live key %d was expired", key)
+		}
+		removed++
+	})
+
+	// Sampling stops once a sample is at most a quarter expired, so not
+	// necessarily every expired key is gone
+	if removed == 0 {
+		t.Errorf("Expected the active cycle to remove expired keys")
+	}
+	if queue.len() != 2000-removed {
+		t.Errorf("Expected %d tracked keys, got %d", 2000-removed, queue.len())
+	}
+
+	// When everything is expired the cycle runs until the queue is empty
+	queue.expire(start.Add(2*time.Hour), 10000, func(int) {})
+	if queue.len() != 0 {
+		t.Errorf("Expected every key to expire, got %d left", queue.len())
+	}
+}
+
+// TestSamplingExpiration tests a cache using the sampling backend
+func TestSamplingExpiration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[int, int](&Config{Clock: clock, Expiration: ExpirationSampling})
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.SetWithTTL(i, &i, time.Second)
+	}
+	for i := 100; i < 110; i++ {
+		cache.Set(i, &i)
+	}
+
+	clock.Advance(time.Second)
+	// Lazy expiry on access
+	if _, found := cache.Get(0); found {
+		t.Errorf("Expired item should not be returned")
+	}
+
+	// The active cycle runs every 100ms by default
+	clock.Advance(activeExpireInterval)
+	if !waitFor(t, func() bool { return cache.Len() == 10 }) {
+		t.Errorf("Expected the active cycle to remove every expired item, got %d items", cache.Len())
+	}
+}
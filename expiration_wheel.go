@@ -0,0 +1,261 @@
+package goinmemcache
+
+import "time"
+
+const (
+	wheelTick    = 10 * time.Millisecond // resolution of the timing wheel
+	wheelBits    = 6
+	wheelSlots   = 1 << wheelBits // slots per level
+	wheelMask    = wheelSlots - 1
+	wheelLevels  = 6    // 64^6 ticks of 10ms cover about 21 years
+	wheelFreeMax = 1024 // removed entries kept for reuse
+)
+
+// wheelEntry is a key scheduled in the timing wheel. Entries of a slot
+// form a circular doubly-linked list around the slot's sentinel.
+type wheelEntry[K comparable] struct {
+	key        K
+	expireTick int64
+	prev, next *wheelEntry[K]
+}
+
+// timingWheel is a hierarchical timing wheel. Level 0 has one slot per
+// tick, and each higher level has slots 64 times wider than the level
+// below. Keys are placed in the lowest level that covers their deadline
+// and cascade down as the wheel turns, so scheduling and removing a key
+// are O(1) regardless of how many keys are tracked.
+type timingWheel[K comparable] struct {
+	tick    time.Duration
+	current int64 // next tick to process
+	slots   [wheelLevels][wheelSlots]wheelEntry[K]
+	entries map[K]*wheelEntry[K]
+	free    []*wheelEntry[K]
+}
+
+func newTimingWheel[K comparable](tick time.Duration, now time.Time) *timingWheel[K] {
+	w := &timingWheel[K]{
+		tick:    tick,
+		entries: make(map[K]*wheelEntry[K]),
+	}
+	w.current = w.tickOf(now)
+	w.initSlots()
+	return w
+}
+
+// initSlots empties every slot by pointing its sentinel at itself
+func (w *timingWheel[K]) initSlots() {
+	for level := range w.slots {
+		for slot := range w.slots[level] {
+			head := &w.slots[level][slot]
+			head.prev, head.next = head, head
+		}
+	}
+}
+
+// tickOf returns the tick that contains t
+func (w *timingWheel[K]) tickOf(t time.Time) int64 {
+	return t.UnixNano() / int64(w.tick)
+}
+
+// timeOf returns when a tick starts
+func (w *timingWheel[K]) timeOf(tick int64) time.Time {
+	return time.Unix(0, tick*int64(w.tick))
+}
+
+func (w *timingWheel[K]) schedule(key K, expireTime time.Time) {
+	// Round up so a key never fires before its expiration time
+	expireTick := (expireTime.UnixNano() + int64(w.tick) - 1) / int64(w.tick)
+
+	if entry, exists := w.entries[key]; exists {
+		unlinkWheelEntry(entry)
+		entry.expireTick = expireTick
+		w.place(entry)
+		return
+	}
+
+	var entry *wheelEntry[K]
+	if n := len(w.free); n > 0 {
+		entry = w.free[n-1]
+		w.free = w.free[:n-1]
+	} else {
+		entry = &wheelEntry[K]{}
+	}
+	entry.key = key
+	entry.expireTick = expireTick
+	w.entries[key] = entry
+	w.place(entry)
+}
+
+// place links an entry into the slot covering its deadline
+func (w *timingWheel[K]) place(entry *wheelEntry[K]) {
+	delta := max(entry.expireTick-w.current, 0)
+	tick := w.current + delta
+	if span := int64(1) << (wheelBits * wheelLevels); delta >= span {
+		// Park far deadlines in the last slot; they are placed again
+		// when that slot cascades
+		tick = w.current + span - 1
+		delta = span - 1
+	}
+
+	level := 0
+	for level < wheelLevels-1 && delta >= int64(1)<<(wheelBits*(level+1)) {
+		level++
+	}
+	slot := (tick >> (wheelBits * level)) & wheelMask
+
+	head := &w.slots[level][slot]
+	entry.prev = head.prev
+	entry.next = head
+	head.prev.next = entry
+	head.prev = entry
+}
+
+func (w *timingWheel[K]) remove(key K) {
+	if entry, exists := w.entries[key]; exists {
+		unlinkWheelEntry(entry)
+		delete(w.entries, key)
+		w.recycle(entry)
+	}
+}
+
+func (w *timingWheel[K]) expire(now time.Time, limit int, fn func(key K)) bool {
+	target := w.tickOf(now)
+	expired := 0
+	for w.current <= target {
+		if len(w.entries) == 0 {
+			// Nothing to turn through
+			w.current = target + 1
+			return false
+		}
+
+		head := &w.slots[0][w.current&wheelMask]
+		for head.next != head {
+			if expired == limit {
+				return true // Resume from this slot on the next call
+			}
+			entry := head.next
+			unlinkWheelEntry(entry)
+			delete(w.entries, entry.key)
+			key := entry.key
+			w.recycle(entry)
+			fn(key)
+			expired++
+		}
+
+		// Jump over empty ticks straight to the next slot or cascade that
+		// holds entries, so a long gap costs no more than a short one
+		next := w.nextTick(w.current + 1)
+		if next > target+1 {
+			w.current = target + 1
+			break
+		}
+		w.current = next
+		if w.current&wheelMask == 0 {
+			w.cascade()
+		}
+	}
+	return false
+}
+
+// nextTick returns the first tick at or after from, and after the current
+// tick, at which a level 0 slot holds entries or a higher level slot holding
+// entries cascades. The wheel must not be empty.
+func (w *timingWheel[K]) nextTick(from int64) int64 {
+	best := int64(-1)
+	// Level 0 holds deadlines within one rotation of the current tick
+	for tick := from; tick < w.current+wheelSlots; tick++ {
+		if head := &w.slots[0][tick&wheelMask]; head.next != head {
+			best = tick
+			break
+		}
+	}
+
+	// Higher levels only cascade at level 0 boundaries, so a level 0 hit
+	// before the next boundary needs no further search
+	after := max(from, w.current+1)
+	if best >= 0 && best < (after+wheelMask)&^wheelMask {
+		return best
+	}
+	for level := 1; level < wheelLevels; level++ {
+		shift := uint(wheelBits * level)
+		boundary := ((after-1)>>shift + 1) << shift // first slot start >= after
+		index := boundary >> shift
+		for i := int64(0); i < wheelSlots; i++ {
+			if head := &w.slots[level][(index+i)&wheelMask]; head.next != head {
+				if tick := boundary + i<<shift; best < 0 || tick < best {
+					best = tick
+				}
+				break
+			}
+		}
+	}
+	return best
+}
+
+// cascade moves the entries of the higher level slots that start at the
+// current tick down to the levels below
+func (w *timingWheel[K]) cascade() {
+	for level := 1; level < wheelLevels; level++ {
+		slot := (w.current >> (wheelBits * level)) & wheelMask
+		head := &w.slots[level][slot]
+
+		// Detach the whole slot before placing its entries again
+		entry := head.next
+		head.prev.next = nil
+		head.prev, head.next = head, head
+		for entry != head && entry != nil {
+			next := entry.next
+			w.place(entry)
+			entry = next
+		}
+
+		if slot != 0 {
+			break // Higher levels only turn when this level wraps
+		}
+	}
+}
+
+// next returns the start of the first non-empty level 0 slot, or of the
+// first cascade that moves entries down. Far deadlines therefore wake the
+// precise timer once per level they cascade through rather than every
+// rotation.
+func (w *timingWheel[K]) next() (time.Time, bool) {
+	if len(w.entries) == 0 {
+		return time.Time{}, false
+	}
+	return w.timeOf(w.nextTick(w.current)), true
+}
+
+func (w *timingWheel[K]) each(fn func(key K) bool) {
+	for key := range w.entries {
+		if !fn(key) {
+			return
+		}
+	}
+}
+
+func (w *timingWheel[K]) len() int {
+	return len(w.entries)
+}
+
+func (w *timingWheel[K]) reset() {
+	w.initSlots()
+	w.entries = make(map[K]*wheelEntry[K])
+	w.free = nil
+}
+
+// recycle keeps a removed entry for reuse by a later schedule
+func (w *timingWheel[K]) recycle(entry *wheelEntry[K]) {
+	var zeroK K
+	entry.key = zeroK
+	if len(w.free) < wheelFreeMax {
+		w.free = append(w.free, entry)
+	}
+}
+
+// unlinkWheelEntry removes an entry from its slot
+func unlinkWheelEntry[K comparable](entry *wheelEntry[K]) {
+	entry.prev.next = entry.next
+	entry.next.prev = entry.prev
+	entry.prev, entry.next = nil, nil
+}
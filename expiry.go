@@ -0,0 +1,187 @@
+package goinmemcache
+
+import (
+	"math"
+	"time"
+)
+
+// NeverExpire is returned by an Expiry to keep an item without expiration.
+// It is also the remaining lifetime reported for items that never expire.
+const NeverExpire = time.Duration(math.MaxInt64)
+
+// Expiry computes per-item lifetimes, in the style of Caffeine's Expiry.
+// Each method returns the item's new lifetime measured from now: returning
+// remaining keeps the current expiration, NeverExpire removes it, and zero
+// or a negative duration expires the item immediately. The cache consults
+// it for Set and SetWithCost (SetWithTTL keeps its explicit TTL) and for
+// every successful Get, while holding its write lock.
+type Expiry[K comparable, V any] interface {
+	// ExpireAfterCreate returns the lifetime of a newly added item
+	ExpireAfterCreate(key K, value *V, now time.Time) time.Duration
+	// ExpireAfterUpdate returns the lifetime of an item after it is replaced
+	ExpireAfterUpdate(key K, value *V, now time.Time, remaining time.Duration) time.Duration
+	// ExpireAfterRead returns the lifetime of an item after it is read
+	ExpireAfterRead(key K, value *V, now time.Time, remaining time.Duration) time.Duration
+}
+
+// remainingLifetime returns how long an item has left, or NeverExpire
+func (c *cache[K, V]) remainingLifetime(item *cacheItem[K, V], now time.Time) time.Duration {
+	expireTime, expires := c.expireTime(item)
+	if !expires {
+		return NeverExpire
+	}
+	return expireTime.Sub(now)
+}
+
+// expiryTTL asks the configured Expiry for the lifetime of an item being
+// written and returns it as a TTL, or nil if the item should not expire
+func (c *cache[K, V]) expiryTTL(key K, value *V, now time.Time) *time.Duration {
+	var ttl time.Duration
+	if existing, exists := c.items[key]; exists {
+		ttl = c.expiry.ExpireAfterUpdate(key, value, now, c.remainingLifetime(existing, now))
+	} else {
+		ttl = c.expiry.ExpireAfterCreate(key, value, now)
+	}
+	if ttl == NeverExpire {
+		return nil
+	}
+	return &ttl
+}
+
+// applyReadExpiry asks the configured Expiry for an item's lifetime after a
+// read and reschedules or removes the item accordingly. A new lifetime is
+// jittered like a TTL given on write.
+func (c *cache[K, V]) applyReadExpiry(key K, item *cacheItem[K, V]) {
+	now := c.clock.Now()
+	remaining := c.remainingLifetime(item, now)
+	ttl := c.expiry.ExpireAfterRead(key, item.Value, now, remaining)
+
+	switch {
+	case ttl == remaining:
+		// Unchanged
+	case ttl == NeverExpire:
+		item.TTL = nil
+		item.MaxExpiry = time.Time{}
+		c.removeExpirationEntry(key)
+	case ttl <= 0:
+		c.removeItemByKey(key)
+	default:
+		ttl = c.jitterTTL(ttl)
+		item.CreatedAt = now
+		item.TTL = &ttl
+		expireTime, _ := c.expireTime(item)
+		c.scheduleExpiration(key, expireTime)
+	}
+}
+
+// SetWithDeadline stores a value that expires at the given wall-clock time.
+// A deadline that has already passed, including the zero time, removes the
+// item without evicting anything.
+func (c *cache[K, V]) SetWithDeadline(key K, value *V, deadline time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if deadline.IsZero() {
+		// The zero time is long past; without this check setItem would
+		// read it as no deadline and store the item without expiration
+		c.removeItemByKey(key)
+		return nil
+	}
+
+	return c.setItem(key, value, itemOptions{cost: defaultItemCost, deadline: deadline})
+}
+
+// ExpireAt sets an absolute expiration time on an existing item, replacing
+// any TTL or sliding expiration. It reports false if the key is not in the
+// cache.
+func (c *cache[K, V]) ExpireAt(key K, deadline time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, exists := c.items[key]
+	if !exists || !c.isItemValid(item) {
+		return false
+	}
+
+	now := c.clock.Now()
+	ttl := deadline.Sub(now)
+	item.CreatedAt = now
+	item.TTL = &ttl
+	item.Sliding = false
+	item.MaxExpiry = time.Time{}
+
+	if ttl <= 0 {
+		c.removeItemByKey(key)
+	} else {
+		c.scheduleExpiration(key, deadline)
+	}
+	return true
+}
+
+// TTL returns the remaining lifetime of an item, or NeverExpire if it has
+// no expiration. It reports false if the key is not in the cache.
+func (c *cache[K, V]) TTL(key K) (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.items[key]
+	if !exists || !c.isItemValid(item) {
+		return 0, false
+	}
+	return c.remainingLifetime(item, c.clock.Now()), true
+}
+
+// Persist removes the expiration of an item so it is only evicted by
+// capacity limits. It reports false if the key is not in the cache.
+func (c *cache[K, V]) Persist(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, exists := c.items[key]
+	if !exists || !c.isItemValid(item) {
+		return false
+	}
+
+	item.TTL = nil
+	item.Sliding = false
+	item.MaxExpiry = time.Time{}
+	c.removeExpirationEntry(key)
+	return true
+}
+
+// EntryStatus reports the state of an item returned by GetWithStatus
+type EntryStatus int
+
+const (
+	// EntryMissing means the key is not in the cache or has expired
+	EntryMissing EntryStatus = iota
+	// EntryFresh means the item is within its soft TTL, or has none
+	EntryFresh
+	// EntryStale means the item is past its soft TTL but within its hard
+	// TTL. Callers can serve it while they refresh the value.
+	EntryStale
+)
+
+// SetWithSoftTTL stores a value that becomes stale after softTTL and
+// expires after hardTTL. A softTTL of zero, or one longer than hardTTL,
+// keeps the item fresh until it expires.
+func (c *cache[K, V]) SetWithSoftTTL(key K, value *V, softTTL, hardTTL time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.setItem(key, value, itemOptions{ttl: &hardTTL, cost: defaultItemCost, softTTL: softTTL})
+}
+
+// GetWithStatus returns the value for a key and whether it is fresh, stale
+// or missing. Stale items are still returned.
+func (c *cache[K, V]) GetWithStatus(key K) (*V, EntryStatus) {
+	return c.getWithStatus(key)
+}
+
+// itemStatus reports whether a valid item is past its soft TTL
+func (c *cache[K, V]) itemStatus(item *cacheItem[K, V]) EntryStatus {
+	if !item.StaleAt.IsZero() && !c.clock.Now().Before(item.StaleAt) {
+		return EntryStale
+	}
+	return EntryFresh
+}
@@ -0,0 +1,387 @@
+package goinmemcache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type token struct {
+	Subject string
+	Exp     time.Time
+}
+
+// tokenExpiry expires tokens at their own exp claim and extends the
+// lifetime of a token by readExtension whenever it is read
+type tokenExpiry struct {
+	readExtension time.Duration
+}
+
+func (e tokenExpiry) ExpireAfterCreate(key string, value *token, now time.Time) time.Duration {
+	if value == nil {
+		return NeverExpire
+	}
+	return value.Exp.Sub(now)
+}
+
+func (e tokenExpiry) ExpireAfterUpdate(key string, value *token, now time.Time, remaining time.Duration) time.Duration {
+	return e.ExpireAfterCreate(key, value, now)
+}
+
+func (e tokenExpiry) ExpireAfterRead(key string, value *token, now time.Time, remaining time.Duration) time.Duration {
+	if e.readExtension == 0 {
+		return remaining
+	}
+	return e.readExtension
+}
+
+// TestExpiryAfterCreate tests that lifetimes are computed from the value
+func TestExpiryAfterCreate(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New(&Config{Clock: clock}, WithExpiry[string, token](tokenExpiry{}))
+	defer cache.Close()
+
+	short := token{Subject: "short", Exp: clock.Now().Add(30 * time.Second)}
+	long := token{Subject: "long", Exp: clock.Now().Add(time.Hour)}
+	cache.Set("short", &short)
+	cache.Set("long", &long)
+
+	if remaining, _ := cache.TTL("short"); remaining != 30*time.Second {
+		t.Errorf("short token should live until its exp claim, got %v", remaining)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, found := cache.Get("short"); found {
+		t.Errorf("short token should have expired at its exp claim")
+	}
+	if _, found := cache.Get("long"); !found {
+		t.Errorf("long token should still be valid")
+	}
+
+	// An explicit TTL still wins over the Expiry
+	cache.SetWithTTL("explicit", &short, time.Hour)
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("explicit"); !found {
+		t.Errorf("SetWithTTL should keep its explicit TTL")
+	}
+}
+
+// TestExpiryAfterUpdate tests that replacing a value recomputes its lifetime
+func TestExpiryAfterUpdate(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New(&Config{Clock: clock}, WithExpiry[string, token](tokenExpiry{}))
+	defer cache.Close()
+
+	first := token{Exp: clock.Now().Add(20 * time.Second)}
+	cache.Set("token", &first)
+
+	refreshed := token{Exp: clock.Now().Add(time.Hour)}
+	cache.Set("token", &refreshed)
+
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("token"); !found {
+		t.Errorf("Updated token should use its new exp claim")
+	}
+
+	// NeverExpire keeps an item without expiration
+	cache.Set("forever", nil)
+	clock.Advance(24 * time.Hour)
+	if _, found := cache.Get("forever"); !found {
+		t.Errorf("Item with NeverExpire lifetime should be present")
+	}
+}
+
+// TestExpiryAfterRead tests that reads can reschedule expiration
+func TestExpiryAfterRead(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New(&Config{Clock: clock}, WithExpiry[string, token](tokenExpiry{readExtension: time.Minute}))
+	defer cache.Close()
+
+	value := token{Exp: clock.Now().Add(30 * time.Second)}
+	cache.Set("token", &value)
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(20 * time.Second)
+		if _, found := cache.Get("token"); !found {
+			t.Fatalf("Reads should keep extending the token (read %d)", i)
+		}
+	}
+	if remaining, _ := cache.TTL("token"); remaining != time.Minute {
+		t.Errorf("A read should set the extended lifetime, got %v", remaining)
+	}
+
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("token"); found {
+		t.Errorf("Token should expire once reads stop")
+	}
+}
+
+// TestSetWithDeadline tests that items expire at an absolute time
+func TestSetWithDeadline(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock})
+	defer cache.Close()
+
+	value := "billing-period"
+	deadline := clock.Now().Add(40 * time.Second)
+	cache.SetWithDeadline("period", &value, deadline)
+
+	if remaining, found := cache.TTL("period"); !found || remaining != 40*time.Second {
+		t.Errorf("Expected 40s remaining, got %v (found %v)", remaining, found)
+	}
+
+	clock.Advance(39 * time.Second)
+	if _, found := cache.Get("period"); !found {
+		t.Errorf("Item should be valid before its deadline")
+	}
+	clock.Advance(time.Second)
+	if _, found := cache.Get("period"); found {
+		t.Errorf("Item should have expired at its deadline")
+	}
+
+	// A deadline in the past removes the item
+	cache.SetWithDeadline("past", &value, clock.Now().Add(-time.Second))
+	if _, found := cache.Get("past"); found {
+		t.Errorf("Item with a past deadline should not be stored")
+	}
+}
+
+// TestPastDeadlineEvictsNothing tests that a write that would expire on
+// arrival does not make room for itself
+func TestPastDeadlineEvictsNothing(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	maxItems := int64(2)
+	cache := New[string, string](&Config{Clock: clock, MaxItems: &maxItems})
+	defer cache.Close()
+
+	value := "value"
+	cache.Set("a", &value)
+	cache.Set("b", &value)
+
+	cache.SetWithDeadline("past", &value, clock.Now().Add(-time.Second))
+	cache.SetWithDeadline("zero", &value, time.Time{})
+	if cache.Len() != 2 {
+		t.Errorf("Expired writes should not evict live items, got %d items", cache.Len())
+	}
+	if _, found := cache.TTL("zero"); found {
+		t.Errorf("A zero deadline should not store the item")
+	}
+
+	// An existing item is removed by a past deadline
+	cache.SetWithDeadline("a", &value, clock.Now().Add(-time.Second))
+	if _, found := cache.Get("a"); found {
+		t.Errorf("A past deadline should remove the existing item")
+	}
+}
+
+// TestExpireAtTTLAndPersist tests changing and querying an existing item's lifetime
+func TestExpireAtTTLAndPersist(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock})
+	defer cache.Close()
+
+	value := "value"
+	cache.Set("key", &value)
+
+	if remaining, found := cache.TTL("key"); !found || remaining != NeverExpire {
+		t.Errorf("Item without TTL should report NeverExpire, got %v", remaining)
+	}
+	if _, found := cache.TTL("missing"); found {
+		t.Errorf("TTL should report false for a missing key")
+	}
+	if cache.ExpireAt("missing", clock.Now().Add(time.Hour)) {
+		t.Errorf("ExpireAt should report false for a missing key")
+	}
+
+	if !cache.ExpireAt("key", clock.Now().Add(30*time.Second)) {
+		t.Fatalf("ExpireAt should succeed for an existing key")
+	}
+	if remaining, _ := cache.TTL("key"); remaining != 30*time.Second {
+		t.Errorf("Expected 30s remaining, got %v", remaining)
+	}
+
+	if !cache.Persist("key") {
+		t.Fatalf("Persist should succeed for an existing key")
+	}
+	clock.Advance(time.Minute)
+	if _, found := cache.Get("key"); !found {
+		t.Errorf("Persisted item should not expire")
+	}
+
+	cache.ExpireAt("key", clock.Now().Add(-time.Second))
+	if _, found := cache.Get("key"); found {
+		t.Errorf("ExpireAt with a past deadline should remove the item")
+	}
+}
+
+// TestDefaultTTL tests that Set and SetWithCost apply Config.DefaultTTL
+func TestDefaultTTL(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock, DefaultTTL: 30 * time.Second})
+	defer cache.Close()
+
+	value := "value"
+	cache.Set("default", &value)
+	cache.SetWithTTL("explicit", &value, time.Hour)
+	cache.SetWithCost("costed", &value, 10)
+
+	if remaining, _ := cache.TTL("default"); remaining != 30*time.Second {
+		t.Errorf("Set should apply the default TTL, got %v", remaining)
+	}
+
+	clock.Advance(30 * time.Second)
+	if _, found := cache.Get("default"); found {
+		t.Errorf("Item stored with Set should expire after the default TTL")
+	}
+	if _, found := cache.Get("explicit"); !found {
+		t.Errorf("SetWithTTL should keep its explicit TTL")
+	}
+	if _, found := cache.Get("costed"); found {
+		t.Errorf("Item stored with SetWithCost should expire after the default TTL")
+	}
+}
+
+// TestTTLJitter tests that jitter spreads expirations within the configured range
+func TestTTLJitter(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	ttl := time.Hour
+	jitter := time.Minute
+	cache := New[int, int](&Config{Clock: clock, TTLJitter: jitter})
+	defer cache.Close()
+
+	distinct := make(map[time.Duration]struct{})
+	for i := 0; i < 50; i++ {
+		cache.SetWithTTL(i, &i, ttl)
+		remaining, _ := cache.TTL(i)
+		if remaining < ttl || remaining >= ttl+jitter {
+			t.Fatalf("Remaining lifetime %v outside [%v, %v)", remaining, ttl, ttl+jitter)
+		}
+		distinct[remaining] = struct{}{}
+	}
+	if len(distinct) < 10 {
+		t.Errorf("Expected jittered TTLs to differ, got %d distinct values", len(distinct))
+	}
+
+	// Absolute deadlines are kept exactly
+	value := 0
+	cache.SetWithDeadline(-1, &value, clock.Now().Add(ttl))
+	if remaining, _ := cache.TTL(-1); remaining != ttl {
+		t.Errorf("Deadline should not be jittered, got %v", remaining)
+	}
+}
+
+// TestTTLJitterOnRead tests that lifetimes renewed by an Expiry on read are
+// jittered and that sliding renewals keep their write-time jitter
+func TestTTLJitterOnRead(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	extension, jitter := time.Hour, time.Minute
+	tokens := New(&Config{Clock: clock, TTLJitter: jitter},
+		WithExpiry[string, token](tokenExpiry{readExtension: extension}))
+	defer tokens.Close()
+
+	distinct := make(map[time.Duration]struct{})
+	for i := 0; i < 50; i++ {
+		tok := token{Exp: clock.Now().Add(time.Minute)}
+		key := fmt.Sprint(i)
+		tokens.Set(key, &tok)
+		tokens.Get(key)
+		remaining, _ := tokens.TTL(key)
+		if remaining < extension || remaining >= extension+jitter {
+			t.Fatalf("Renewed lifetime %v outside [%v, %v)", remaining, extension, extension+jitter)
+		}
+		distinct[remaining] = struct{}{}
+	}
+	if len(distinct) < 10 {
+		t.Errorf("Expected renewed lifetimes to differ, got %d distinct values", len(distinct))
+	}
+
+	sessions := New[string, string](&Config{Clock: clock, TTLJitter: jitter})
+	defer sessions.Close()
+	value := "session"
+	sessions.SetWithSlidingTTL("s", &value, extension)
+	written, _ := sessions.TTL("s")
+	clock.Advance(time.Minute)
+	sessions.Get("s")
+	if renewed, _ := sessions.TTL("s"); renewed != written {
+		t.Errorf("Sliding renewal should reuse the jittered TTL %v, got %v", written, renewed)
+	}
+}
+
+// TestCleanupInterval tests that the background cleanup runs at the configured interval
+func TestCleanupInterval(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock, CleanupInterval: 10 * time.Second})
+	defer cache.Close()
+
+	value := "value"
+	cache.SetWithTTL("key", &value, 5*time.Second)
+
+	clock.Advance(10 * time.Second)
+	if !waitFor(t, func() bool { return cache.Len() == 0 }) {
+		t.Errorf("Expired item should be removed by the background cleanup, got %d items", cache.Len())
+	}
+}
+
+// TestPreciseExpiration tests that the timer removes items close to their expiry
+func TestPreciseExpiration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock, PreciseExpiration: true})
+	defer cache.Close()
+
+	value := "value"
+	cache.SetWithTTL("late", &value, time.Hour)
+	cache.SetWithTTL("second", &value, 40*time.Second)
+	// An earlier expiration re-arms the timer
+	cache.SetWithTTL("first", &value, 20*time.Second)
+
+	clock.Advance(20 * time.Second)
+	if !waitFor(t, func() bool { return cache.Len() == 2 }) {
+		t.Errorf("Expected first item to be removed by the timer, got %d items", cache.Len())
+	}
+
+	clock.Advance(20 * time.Second)
+	if !waitFor(t, func() bool { return cache.Len() == 1 }) {
+		t.Errorf("Expected second item to be removed by the re-armed timer, got %d items", cache.Len())
+	}
+}
+
+// TestSoftTTL tests that items are fresh, then stale, then missing
+func TestSoftTTL(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := New[string, string](&Config{Clock: clock})
+	defer cache.Close()
+
+	value := "rates"
+	cache.SetWithSoftTTL("rates", &value, time.Minute, time.Hour)
+
+	if got, status := cache.GetWithStatus("rates"); status != EntryFresh || got == nil || *got != value {
+		t.Errorf("Expected fresh value before the soft TTL, got status %d", status)
+	}
+
+	clock.Advance(time.Minute)
+	if got, status := cache.GetWithStatus("rates"); status != EntryStale || got == nil || *got != value {
+		t.Errorf("Expected stale value after the soft TTL, got status %d", status)
+	}
+	if _, found := cache.Get("rates"); !found {
+		t.Errorf("Get should still return a stale item")
+	}
+
+	// Refreshing the value makes it fresh again
+	refreshed := "new-rates"
+	cache.SetWithSoftTTL("rates", &refreshed, time.Minute, time.Hour)
+	if _, status := cache.GetWithStatus("rates"); status != EntryFresh {
+		t.Errorf("Expected refreshed value to be fresh, got status %d", status)
+	}
+
+	clock.Advance(time.Hour)
+	if got, status := cache.GetWithStatus("rates"); status != EntryMissing || got != nil {
+		t.Errorf("Expected missing after the hard TTL, got status %d", status)
+	}
+
+	// Items without a soft TTL are always fresh
+	cache.Set("plain", &value)
+	clock.Advance(24 * time.Hour)
+	if _, status := cache.GetWithStatus("plain"); status != EntryFresh {
+		t.Errorf("Expected item without soft TTL to be fresh, got status %d", status)
+	}
+}
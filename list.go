@@ -0,0 +1,86 @@
+package goinmemcache
+
+// listNode represents a node in the doubly-linked list used for key ordering
+type listNode[K comparable] struct {
+	key  K
+	prev *listNode[K]
+	next *listNode[K]
+}
+
+// keyList is a doubly-linked list of keys with dummy head and tail nodes.
+// The front of the list (right after head) holds the oldest key and the
+// back (right before tail) holds the newest one.
+type keyList[K comparable] struct {
+	head *listNode[K] // dummy head node
+	tail *listNode[K] // dummy tail node
+	len  int
+}
+
+func newKeyList[K comparable]() *keyList[K] {
+	l := &keyList[K]{
+		head: &listNode[K]{},
+		tail: &listNode[K]{},
+	}
+	l.head.next = l.tail
+	l.tail.prev = l.head
+	return l
+}
+
+// pushBack adds a node right before the tail (newest position)
+func (l *keyList[K]) pushBack(node *listNode[K]) {
+	prev := l.tail.prev
+	prev.next = node
+	node.prev = prev
+	node.next = l.tail
+	l.tail.prev = node
+	l.len++
+}
+
+// remove unlinks a node from the list
+func (l *keyList[K]) remove(node *listNode[K]) {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+	node.prev = nil
+	node.next = nil
+	l.len--
+}
+
+// moveToBack moves a node to the newest position
+func (l *keyList[K]) moveToBack(node *listNode[K]) {
+	l.remove(node)
+	l.pushBack(node)
+}
+
+// front returns the oldest node, or nil if the list is empty
+func (l *keyList[K]) front() *listNode[K] {
+	if l.head.next == l.tail {
+		return nil
+	}
+	return l.head.next
+}
+
+// popFront removes and returns the oldest node, or nil if the list is empty
+func (l *keyList[K]) popFront() *listNode[K] {
+	node := l.front()
+	if node != nil {
+		l.remove(node)
+	}
+	return node
+}
+
+// firstMatch returns the oldest node for which skip returns false
+func (l *keyList[K]) firstMatch(skip func(K) bool) *listNode[K] {
+	for node := l.head.next; node != l.tail; node = node.next {
+		if skip == nil || !skip(node.key) {
+			return node
+		}
+	}
+	return nil
+}
+
+// reset empties the list
+func (l *keyList[K]) reset() {
+	l.head.next = l.tail
+	l.tail.prev = l.head
+	l.len = 0
+}
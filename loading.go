@@ -0,0 +1,283 @@
+package goinmemcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoLoader is returned by GetOrLoad when neither a loader nor a
+	// default loader is available for a missing key
+	ErrNoLoader = errors.New("goinmemcache: no loader for missing key")
+	// ErrNotLoaded is returned by GetOrLoad when the key was part of a
+	// bulk load whose result did not include it
+	ErrNotLoaded = errors.New("goinmemcache: bulk loader returned no value for key")
+)
+
+// Loader computes the value of a key that is missing from the cache
+type Loader[K comparable, V any] func(ctx context.Context, key K) (*V, error)
+
+// BulkLoader computes the values of several missing keys in one call, such
+// as a SQL IN query or a multi-get. Keys left out of the result are
+// treated as not found.
+type BulkLoader[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// LoadingCache is a Cache that loads missing values on demand. Concurrent
+// misses for the same key share a single load.
+type LoadingCache[K comparable, V any] interface {
+	Cache[K, V]
+	// GetOrLoad returns the cached value for key, or loads and stores it
+	// with loader, or with the default loader when loader is nil
+	GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (*V, error)
+	// GetAll returns the values of the keys that are cached or could be
+	// loaded. Misses are loaded in one call with the loader passed through
+	// WithBulkLoader, or one by one with the default loader without one.
+	GetAll(ctx context.Context, keys []K) (map[K]*V, error)
+}
+
+// loadCall is a load in flight that concurrent callers wait on
+type loadCall[V any] struct {
+	done  chan struct{}
+	value *V
+	err   error
+}
+
+// batchEntry is a key waiting for a bulk load together with its call
+type batchEntry[K comparable, V any] struct {
+	key  K
+	call *loadCall[V]
+}
+
+type loadingCache[K comparable, V any] struct {
+	Cache[K, V]
+	loader      Loader[K, V]     // default loader, may be nil
+	bulkLoader  BulkLoader[K, V] // may be nil
+	batchWindow time.Duration
+	clock       Clock
+
+	mu       sync.Mutex
+	loading  map[K]*loadCall[V]
+	batch    []batchEntry[K, V] // keys waiting for the coalescing window to close
+	batchCtx context.Context    // context of the caller that opened the window
+}
+
+// NewLoadingCache creates a LoadingCache with the given configuration,
+// default loader and options. The default loader may be nil if every
+// GetOrLoad call supplies its own.
+func NewLoadingCache[K comparable, V any](config *Config, loader Loader[K, V], opts ...Option[K, V]) LoadingCache[K, V] {
+	if config == nil {
+		config = &Config{}
+	}
+
+	c := &loadingCache[K, V]{
+		Cache:       New(config, opts...),
+		loader:      loader,
+		bulkLoader:  newOptions(opts).bulkLoader,
+		batchWindow: config.BatchWindow,
+		clock:       config.Clock,
+		loading:     make(map[K]*loadCall[V]),
+	}
+	if c.clock == nil {
+		c.clock = systemClock{}
+	}
+	return c
+}
+
+// GetOrLoad returns the cached value for key or loads it. Only one load
+// per key runs at a time; other callers wait for its result. The load is
+// not cancelled when the caller that started it gives up, so its result
+// can still be cached for the others. Loader errors are returned to every
+// waiter and are not cached.
+func (c *loadingCache[K, V]) GetOrLoad(ctx context.Context, key K, loader Loader[K, V]) (*V, error) {
+	if value, found := c.Get(key); found {
+		return value, nil
+	}
+	if loader == nil {
+		loader = c.loader
+	}
+	if loader == nil {
+		return nil, ErrNoLoader
+	}
+
+	c.mu.Lock()
+	call, inFlight := c.loading[key]
+	if !inFlight {
+		// A load may have finished since the first lookup
+		if value, found := c.Get(key); found {
+			c.mu.Unlock()
+			return value, nil
+		}
+		call = &loadCall[V]{done: make(chan struct{})}
+		c.loading[key] = call
+		go c.load(context.WithoutCancel(ctx), key, loader, call)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load runs a loader, stores a successful result and wakes the waiters
+func (c *loadingCache[K, V]) load(ctx context.Context, key K, loader Loader[K, V], call *loadCall[V]) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.value, call.err = nil, fmt.Errorf("goinmemcache: loader panicked: %v", r)
+		}
+		if call.err == nil {
+			// The value is returned even if the cache has no room for it
+			_ = c.Set(key, call.value)
+		}
+
+		c.mu.Lock()
+		delete(c.loading, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = loader(ctx, key)
+}
+
+// GetAll serves cached keys directly and loads the misses. Keys already
+// being loaded by another caller are waited on instead of loaded again.
+// Loaded values are stored with the cache's configured TTL; keys the
+// loader does not return are left out of the result.
+func (c *loadingCache[K, V]) GetAll(ctx context.Context, keys []K) (map[K]*V, error) {
+	result := make(map[K]*V, len(keys))
+	var misses []K
+	for _, key := range keys {
+		if value, found := c.Get(key); found {
+			result[key] = value
+		} else {
+			misses = append(misses, key)
+		}
+	}
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	if c.bulkLoader == nil {
+		for _, key := range misses {
+			value, err := c.GetOrLoad(ctx, key, nil)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+
+	waiting := make(map[K]*loadCall[V], len(misses))
+	var own []batchEntry[K, V]
+
+	c.mu.Lock()
+	for _, key := range misses {
+		if _, seen := waiting[key]; seen {
+			continue
+		}
+		if call, inFlight := c.loading[key]; inFlight {
+			waiting[key] = call
+			continue
+		}
+		// A load may have finished since the first lookup
+		if value, found := c.Get(key); found {
+			result[key] = value
+			continue
+		}
+		call := &loadCall[V]{done: make(chan struct{})}
+		c.loading[key] = call
+		waiting[key] = call
+		own = append(own, batchEntry[K, V]{key: key, call: call})
+	}
+	if len(own) > 0 {
+		if c.batchWindow > 0 {
+			if len(c.batch) == 0 {
+				// Open a window for concurrent callers to join
+				c.batchCtx = context.WithoutCancel(ctx)
+				timer := c.clock.NewTimer(c.batchWindow)
+				go func() {
+					<-timer.C()
+					c.flushBatch()
+				}()
+			}
+			c.batch = append(c.batch, own...)
+		} else {
+			go c.bulkLoad(context.WithoutCancel(ctx), own)
+		}
+	}
+	c.mu.Unlock()
+
+	for key, call := range waiting {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if errors.Is(call.err, ErrNotLoaded) {
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		result[key] = call.value
+	}
+	return result, nil
+}
+
+// flushBatch bulk loads the keys collected during a coalescing window
+func (c *loadingCache[K, V]) flushBatch() {
+	c.mu.Lock()
+	entries, ctx := c.batch, c.batchCtx
+	c.batch, c.batchCtx = nil, nil
+	c.mu.Unlock()
+
+	c.bulkLoad(ctx, entries)
+}
+
+// bulkLoad runs the bulk loader for a batch, stores the values it returns
+// and wakes the waiters of every key in the batch
+func (c *loadingCache[K, V]) bulkLoad(ctx context.Context, entries []batchEntry[K, V]) {
+	keys := make([]K, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key
+	}
+
+	values, err := c.callBulkLoader(ctx, keys)
+	for _, entry := range entries {
+		switch value, found := values[entry.key]; {
+		case err != nil:
+			entry.call.err = err
+		case !found:
+			entry.call.err = ErrNotLoaded
+		default:
+			entry.call.value = &value
+			// The value is returned even if the cache has no room for it
+			_ = c.Set(entry.key, entry.call.value)
+		}
+	}
+
+	c.mu.Lock()
+	for _, entry := range entries {
+		delete(c.loading, entry.key)
+	}
+	c.mu.Unlock()
+	for _, entry := range entries {
+		close(entry.call.done)
+	}
+}
+
+// callBulkLoader runs the bulk loader, turning a panic into an error
+func (c *loadingCache[K, V]) callBulkLoader(ctx context.Context, keys []K) (values map[K]V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			values, err = nil, fmt.Errorf("goinmemcache: bulk loader panicked: %v", r)
+		}
+	}()
+	return c.bulkLoader(ctx, keys)
+}
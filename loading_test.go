@@ -0,0 +1,267 @@
+package goinmemcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGetOrLoadSingleflight tests that concurrent misses share one load
+func TestGetOrLoadSingleflight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (*string, error) {
+		calls.Add(1)
+		<-release
+		value := "loaded-" + key
+		return &value, nil
+	}
+	cache := NewLoadingCache[string, string](&Config{}, loader)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	results := make([]string, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := cache.GetOrLoad(context.Background(), "user", nil)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			results[i] = *value
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond) // let the callers pile up on the load
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single load, got %d", calls.Load())
+	}
+	for i, result := range results {
+		if result != "loaded-user" {
+			t.Errorf("Caller %d got %q", i, result)
+		}
+	}
+	if value, found := cache.Get("user"); !found || *value != "loaded-user" {
+		t.Errorf("Loaded value should be cached")
+	}
+
+	// Later calls are served from the cache
+	cache.GetOrLoad(context.Background(), "user", nil)
+	if calls.Load() != 1 {
+		t.Errorf("Cached value should not be loaded again, got %d loads", calls.Load())
+	}
+}
+
+// TestGetOrLoadErrors tests that errors reach every waiter and are not cached
+func TestGetOrLoadErrors(t *testing.T) {
+	errDown := errors.New("database down")
+	var calls atomic.Int32
+	release := make(chan struct{})
+	failing := func(ctx context.Context, key int) (*int, error) {
+		calls.Add(1)
+		<-release
+		return nil, errDown
+	}
+	cache := NewLoadingCache[int, int](&Config{}, nil)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.GetOrLoad(context.Background(), 1, failing); !errors.Is(err, errDown) {
+				t.Errorf("Expected loader error, got %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if cache.Len() != 0 {
+		t.Errorf("Errors should not be cached")
+	}
+
+	succeeding := func(ctx context.Context, key int) (*int, error) {
+		value := key * 10
+		return &value, nil
+	}
+	if value, err := cache.GetOrLoad(context.Background(), 1, succeeding); err != nil || *value != 10 {
+		t.Errorf("Expected a retry to load the value, got %v, %v", value, err)
+	}
+
+	if _, err := cache.GetOrLoad(context.Background(), 2, nil); !errors.Is(err, ErrNoLoader) {
+		t.Errorf("Expected ErrNoLoader without a loader, got %v", err)
+	}
+}
+
+// TestGetOrLoadCancelledWaiter tests that a cancelled caller returns early
+// while the load completes for the others
+func TestGetOrLoadCancelledWaiter(t *testing.T) {
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (*string, error) {
+		<-release
+		value := "slow"
+		return &value, nil
+	}
+	cache := NewLoadingCache[string, string](&Config{}, loader)
+	defer cache.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.GetOrLoad(ctx, "key", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline error, got %v", err)
+	}
+
+	close(release)
+	value, err := cache.GetOrLoad(context.Background(), "key", nil)
+	if err != nil || *value != "slow" {
+		t.Errorf("Expected the load to finish for later callers, got %v, %v", value, err)
+	}
+}
+
+// TestGetOrLoadPanic tests that a panicking loader does not hang waiters
+func TestGetOrLoadPanic(t *testing.T) {
+	cache := NewLoadingCache[string, string](&Config{}, func(ctx context.Context, key string) (*string, error) {
+		panic("boom")
+	})
+	defer cache.Close()
+
+	if _, err := cache.GetOrLoad(context.Background(), "key", nil); err == nil {
+		t.Errorf("Expected an error from a panicking loader")
+	}
+}
+
+// TestGetAllBulkLoad tests that hits are served from the cache and misses
+// are loaded in one call
+func TestGetAllBulkLoad(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	var batches [][]int
+	var mu sync.Mutex
+	bulk := func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		batches = append(batches, append([]int(nil), keys...))
+		mu.Unlock()
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key != 404 { // unknown to the backend
+				values[key] = fmt.Sprintf("user-%d", key)
+			}
+		}
+		return values, nil
+	}
+	cache := NewLoadingCache(&Config{Clock: clock, DefaultTTL: time.Minute}, nil, WithBulkLoader(bulk))
+	defer cache.Close()
+
+	cached := "cached-1"
+	cache.Set(1, &cached)
+
+	values, err := cache.GetAll(context.Background(), []int{1, 2, 3, 3, 404})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("Expected one bulk load of the 3 distinct misses, got %v", batches)
+	}
+	if *values[1] != "cached-1" || *values[2] != "user-2" || *values[3] != "user-3" {
+		t.Errorf("Unexpected values %v", values)
+	}
+	if _, found := values[404]; found {
+		t.Errorf("Keys the loader did not return should be left out")
+	}
+
+	// Loaded values are stored with the configured TTL
+	if _, found := cache.Get(2); !found {
+		t.Errorf("Loaded value should be cached")
+	}
+	clock.Advance(time.Minute)
+	if _, found := cache.Get(2); found {
+		t.Errorf("Loaded value should expire after the default TTL")
+	}
+}
+
+// TestGetAllCoalescesConcurrentMisses tests that misses from concurrent
+// callers within the window share one bulk load
+func TestGetAllCoalescesConcurrentMisses(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	var calls atomic.Int32
+	var loaded atomic.Int32
+	bulk := BulkLoader[string, int](func(ctx context.Context, keys []string) (map[string]int, error) {
+		calls.Add(1)
+		loaded.Add(int32(len(keys)))
+		values := make(map[string]int)
+		for _, key := range keys {
+			values[key] = len(key)
+		}
+		return values, nil
+	})
+	window := 10 * time.Millisecond
+	lc := NewLoadingCache(&Config{Clock: clock, BatchWindow: window}, nil, WithBulkLoader(bulk))
+	defer lc.Close()
+
+	var wg sync.WaitGroup
+	for _, keys := range [][]string{{"a", "bb"}, {"bb", "ccc"}, {"dddd"}} {
+		wg.Add(1)
+		go func(keys []string) {
+			defer wg.Done()
+			values, err := lc.GetAll(context.Background(), keys)
+			if err != nil || len(values) != len(keys) {
+				t.Errorf("GetAll(%v) = %v, %v", keys, values, err)
+			}
+		}(keys)
+	}
+
+	// Close the window once every caller has joined the batch
+	inFlight := func() bool {
+		c := lc.(*loadingCache[string, int])
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.loading) == 4
+	}
+	if !waitFor(t, inFlight) {
+		t.Fatalf("Callers did not join the batch")
+	}
+	clock.Advance(window)
+	wg.Wait()
+
+	if calls.Load() != 1 || loaded.Load() != 4 {
+		t.Errorf("Expected one bulk load of 4 keys, got %d loads of %d keys", calls.Load(), loaded.Load())
+	}
+}
+
+// TestGetAllErrors tests that bulk loader errors reach every caller and are not cached
+func TestGetAllErrors(t *testing.T) {
+	errDown := errors.New("replica down")
+	cache := NewLoadingCache(&Config{}, nil, WithBulkLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, errDown
+	}))
+	defer cache.Close()
+
+	if _, err := cache.GetAll(context.Background(), []int{1, 2}); !errors.Is(err, errDown) {
+		t.Errorf("Expected bulk loader error, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Errors should not be cached")
+	}
+
+	// Without a bulk loader misses fall back to the default loader
+	fallback := NewLoadingCache[int, int](&Config{}, func(ctx context.Context, key int) (*int, error) {
+		value := key * 2
+		return &value, nil
+	})
+	defer fallback.Close()
+	values, err := fallback.GetAll(context.Background(), []int{1, 2})
+	if err != nil || *values[1] != 2 || *values[2] != 4 {
+		t.Errorf("Expected values from the default loader, got %v, %v", values, err)
+	}
+}
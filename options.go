@@ -0,0 +1,55 @@
+package goinmemcache
+
+// Option supplies a setting whose type depends on the cache's key and value
+// types. Unlike a Config field, a mismatched key or value type is a compile
+// error rather than a panic in New.
+type Option[K comparable, V any] func(*options[K, V])
+
+// options holds the typed settings collected from Option values
+type options[K comparable, V any] struct {
+	evictionPolicy EvictionPolicy[K]
+	weigher        func(K, V) int64
+	expiry         Expiry[K, V]
+	bulkLoader     BulkLoader[K, V]
+}
+
+// newOptions applies opts in order, so later options win
+func newOptions[K comparable, V any](opts []Option[K, V]) *options[K, V] {
+	o := &options[K, V]{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithEvictionPolicy plugs in a custom eviction policy, overriding
+// Config.Eviction
+func WithEvictionPolicy[K comparable, V any](policy EvictionPolicy[K]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.evictionPolicy = policy
+	}
+}
+
+// WithWeigher replaces the built-in size estimate with weigher. It is called
+// on every set and update, and the Size limit is then measured in its units.
+func WithWeigher[K comparable, V any](weigher func(K, V) int64) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.weigher = weigher
+	}
+}
+
+// WithExpiry computes per-item lifetimes with expiry. Set and SetWithCost
+// then ignore Config.DefaultTTL.
+func WithExpiry[K comparable, V any](expiry Expiry[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.expiry = expiry
+	}
+}
+
+// WithBulkLoader makes LoadingCache.GetAll load every missing key with
+// loader in one call. It has no effect on caches created with New.
+func WithBulkLoader[K comparable, V any](loader BulkLoader[K, V]) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.bulkLoader = loader
+	}
+}
@@ -0,0 +1,208 @@
+package goinmemcache
+
+import (
+	"reflect"
+	"sync"
+)
+
+const (
+	mapBucketEntries  = 8   // entries per map bucket
+	mapLoadFactor     = 6.5 // average entries per bucket before a map grows
+	mapBucketOverhead = 16  // tophash bytes plus overflow pointer per bucket
+	mapHeaderSize     = 48  // runtime map header
+)
+
+// sizePlan caches the layout of a type for deep size calculation so the
+// reflection work of inspecting a type happens once per type, not once
+// per value
+type sizePlan struct {
+	kind    reflect.Kind
+	size    int64       // shallow size of the type
+	hasRefs bool        // whether values can reach memory outside their own size
+	fields  []fieldPlan // struct fields that have references
+	elem    *sizePlan   // element plan for arrays, slices, pointers and maps
+	key     *sizePlan   // key plan for maps
+}
+
+// fieldPlan is a struct field that needs to be walked
+type fieldPlan struct {
+	index int
+	plan  *sizePlan
+}
+
+var (
+	sizePlans   sync.Map   // reflect.Type -> *sizePlan
+	sizePlansMu sync.Mutex // serializes plan construction
+)
+
+// planFor returns the cached size plan for a type, building it on first use
+func planFor(t reflect.Type) *sizePlan {
+	if plan, ok := sizePlans.Load(t); ok {
+		return plan.(*sizePlan)
+	}
+
+	sizePlansMu.Lock()
+	defer sizePlansMu.Unlock()
+
+	building := make(map[reflect.Type]*sizePlan)
+	plan := buildPlan(t, building)
+	for typ, p := range building {
+		sizePlans.LoadOrStore(typ, p)
+	}
+	return plan
+}
+
+// buildPlan constructs the plan for a type. Plans under construction are
+// kept in building so recursive types resolve to the same plan.
+func buildPlan(t reflect.Type, building map[reflect.Type]*sizePlan) *sizePlan {
+	if plan, ok := sizePlans.Load(t); ok {
+		return plan.(*sizePlan)
+	}
+	if plan, ok := building[t]; ok {
+		return plan
+	}
+
+	plan := &sizePlan{kind: t.Kind(), size: int64(t.Size())}
+	building[t] = plan
+
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		plan.hasRefs = true
+	case reflect.Pointer, reflect.Slice:
+		plan.hasRefs = true
+		plan.elem = buildPlan(t.Elem(), building)
+	case reflect.Map:
+		plan.hasRefs = true
+		plan.key = buildPlan(t.Key(), building)
+		plan.elem = buildPlan(t.Elem(), building)
+	case reflect.Array:
+		plan.elem = buildPlan(t.Elem(), building)
+		plan.hasRefs = plan.elem.hasRefs && t.Len() > 0
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			fieldPlan := fieldPlan{index: i, plan: buildPlan(t.Field(i).Type, building)}
+			if fieldPlan.plan.hasRefs {
+				plan.fields = append(plan.fields, fieldPlan)
+				plan.hasRefs = true
+			}
+		}
+	}
+	return plan
+}
+
+// visitKey identifies memory that has already been counted
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// sizeWalker computes deep sizes while remembering visited memory so
+// shared and cyclic references are counted once
+type sizeWalker struct {
+	visited map[visitKey]struct{}
+}
+
+// deepValueSize returns the shallow size of value plus everything it
+// references through strings, slices, maps, pointers and interfaces
+func deepValueSize[V any](value V) int64 {
+	v := reflect.ValueOf(&value).Elem()
+	plan := planFor(v.Type())
+	w := &sizeWalker{}
+	return plan.size + w.indirect(v, plan)
+}
+
+// firstVisit records memory at ptr of type t and reports whether it was new
+func (w *sizeWalker) firstVisit(ptr uintptr, t reflect.Type) bool {
+	if w.visited == nil {
+		w.visited = make(map[visitKey]struct{})
+	}
+	key := visitKey{ptr: ptr, typ: t}
+	if _, seen := w.visited[key]; seen {
+		return false
+	}
+	w.visited[key] = struct{}{}
+	return true
+}
+
+// indirect returns the bytes reachable from v that are not part of v itself
+func (w *sizeWalker) indirect(v reflect.Value, plan *sizePlan) int64 {
+	if !plan.hasRefs {
+		return 0
+	}
+
+	switch plan.kind {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Pointer:
+		if v.IsNil() || !w.firstVisit(v.Pointer(), v.Type()) {
+			return 0
+		}
+		return plan.elem.size + w.indirect(v.Elem(), plan.elem)
+
+	case reflect.Slice:
+		if v.IsNil() || !w.firstVisit(v.Pointer(), v.Type()) {
+			return 0
+		}
+		size := int64(v.Cap()) * plan.elem.size
+		if plan.elem.hasRefs {
+			for i := 0; i < v.Len(); i++ {
+				size += w.indirect(v.Index(i), plan.elem)
+			}
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += w.indirect(v.Index(i), plan.elem)
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || !w.firstVisit(v.Pointer(), v.Type()) {
+			return 0
+		}
+		size := mapBucketsSize(v.Len(), plan.key.size+plan.elem.size)
+		if plan.key.hasRefs || plan.elem.hasRefs {
+			iter := v.MapRange()
+			for iter.Next() {
+				size += w.indirect(iter.Key(), plan.key)
+				size += w.indirect(iter.Value(), plan.elem)
+			}
+		}
+		return size
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		concrete := v.Elem()
+		concretePlan := planFor(concrete.Type())
+		// Pointer-shaped values are stored in the interface word itself
+		if concretePlan.kind == reflect.Pointer || concretePlan.kind == reflect.Map {
+			return w.indirect(concrete, concretePlan)
+		}
+		return concretePlan.size + w.indirect(concrete, concretePlan)
+
+	case reflect.Struct:
+		var size int64
+		for _, field := range plan.fields {
+			size += w.indirect(v.Field(field.index), field.plan)
+		}
+		return size
+	}
+	return 0
+}
+
+// mapBucketsSize approximates the memory held by a map's buckets
+func mapBucketsSize(entries int, entrySize int64) int64 {
+	if entries == 0 {
+		return 0
+	}
+	buckets := int64(1)
+	for float64(buckets)*mapLoadFactor < float64(entries) {
+		buckets <<= 1
+	}
+	return mapHeaderSize + buckets*(mapBucketEntries*entrySize+mapBucketOverhead)
+}
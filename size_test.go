@@ -0,0 +1,104 @@
+package goinmemcache
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDeepSizeStringsAndSlices tests that backing arrays are counted
+func TestDeepSizeStringsAndSlices(t *testing.T) {
+	if got, want := deepValueSize("hello"), int64(16+5); got != want {
+		t.Errorf("Expected string size %d, got %d", want, got)
+	}
+
+	values := make([]string, 2, 4)
+	values[0], values[1] = "abc", "defg"
+	// header + 4 string headers in the backing array + 7 string bytes
+	if got, want := deepValueSize(values), int64(24+4*16+7); got != want {
+		t.Errorf("Expected []string size %d, got %d", want, got)
+	}
+}
+
+// TestDeepSizeNestedStruct tests structs holding strings, maps and pointers
+func TestDeepSizeNestedStruct(t *testing.T) {
+	type Inner struct {
+		Name string
+	}
+	type Outer struct {
+		ID    int
+		Blobs map[string][]byte
+		Ptrs  []*Inner
+	}
+
+	value := Outer{
+		ID:    1,
+		Blobs: map[string][]byte{"a": make([]byte, 100), "b": make([]byte, 200)},
+		Ptrs:  []*Inner{{Name: "x"}, {Name: "yz"}},
+	}
+
+	shallow := int64(reflect.TypeOf(value).Size())
+	got := deepValueSize(value)
+	if got < shallow+300+3 {
+		t.Errorf("Deep size %d should include map payloads and pointed-to strings", got)
+	}
+}
+
+// TestDeepSizeCycles tests that cyclic and shared references are counted once
+func TestDeepSizeCycles(t *testing.T) {
+	type Node struct {
+		Label string
+		Next  *Node
+	}
+
+	a := &Node{Label: "a"}
+	b := &Node{Label: "b", Next: a}
+	a.Next = b
+
+	nodeSize := int64(reflect.TypeOf(Node{}).Size())
+	// the pointer itself, both nodes once, and one byte of label each
+	if got, want := deepValueSize(a), int64(8)+2*nodeSize+2; got != want {
+		t.Errorf("Expected cyclic size %d, got %d", want, got)
+	}
+
+	shared := &Node{Label: "shared"}
+	pair := []*Node{shared, shared}
+	if got, want := deepValueSize(pair), int64(24+2*8)+nodeSize+6; got != want {
+		t.Errorf("Expected shared pointer to be counted once: want %d, got %d", want, got)
+	}
+}
+
+// TestDeepSizeConfig tests that Config.DeepSize changes the size limit accounting
+func TestDeepSizeConfig(t *testing.T) {
+	maxSize := int64(1000)
+	shallowCache := New[string, []string](&Config{Size: &maxSize})
+	deepCache := New[string, []string](&Config{Size: &maxSize, DeepSize: true})
+
+	value := []string{string(make([]byte, 600))}
+	for _, c := range []Cache[string, []string]{shallowCache, deepCache} {
+		c.Set("a", &value)
+		c.Set("b", &value)
+	}
+
+	if shallowCache.Len() != 2 {
+		t.Errorf("Shallow estimate should fit both items, got %d", shallowCache.Len())
+	}
+	if deepCache.Len() != 1 {
+		t.Errorf("Deep estimate should only fit one item, got %d", deepCache.Len())
+	}
+}
+
+// TestSizePlansAreCached tests that a type's plan is built once
+func TestSizePlansAreCached(t *testing.T) {
+	type Cached struct {
+		Values []string
+	}
+
+	first := planFor(reflect.TypeOf(Cached{}))
+	second := planFor(reflect.TypeOf(Cached{}))
+	if first != second {
+		t.Errorf("Expected the same cached plan for repeated lookups")
+	}
+	if !first.hasRefs || len(first.fields) != 1 {
+		t.Errorf("Expected one walkable field, got %+v", first)
+	}
+}